@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -25,15 +27,11 @@ type AccessTokenResponse struct {
 	Token string `json:"token"`
 }
 
+var accessTokenEndpoint = NewEndpoint[AccessTokenRequest, AccessTokenResponse](EndpointInfo{Path: "/api/1/access_token", Idempotent: true})
+
 // Retrieve session key for API user.
 //
 // iiko API: /api/1/access_token
-func (c *Client) accessToken(req *AccessTokenRequest, opts ...Option) (*AccessTokenResponse, error) {
-	var response AccessTokenResponse
-
-	if err := c.post(false, "/api/1/access_token", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) accessToken(ctx context.Context, req *AccessTokenRequest, opts ...Option) (*AccessTokenResponse, error) {
+	return accessTokenEndpoint.Call(ctx, c, req, opts...)
 }
@@ -0,0 +1,70 @@
+package iiko
+
+import "context"
+
+// CatalogAPI is the part of the iikoCloud API that describes organizations,
+// terminals, the menu and dictionaries.
+type CatalogAPI interface {
+	Organizations(ctx context.Context, req *OrganizationsRequest, opts ...Option) (*OrganizationsResponse, error)
+	TerminalGroups(ctx context.Context, req *TerminalGroupsRequest, opts ...Option) (*TerminalGroupsResponse, error)
+	TerminalGroupsIsAlive(ctx context.Context, req *TerminalGroupsIsAliveRequest, opts ...Option) (*TerminalGroupsIsAliveResponse, error)
+	Nomenclature(ctx context.Context, req *NomenclatureRequest, opts ...Option) (*NomenclatureResponse, error)
+	Menu(ctx context.Context, opts ...Option) (*MenuResponse, error)
+	MenuById(ctx context.Context, req *MenuByIdRequest, opts ...Option) (*MenuByIdResponse, error)
+	ComboGetCombosInfo(ctx context.Context, req *ComboGetCombosInfoRequest, opts ...Option) (*ComboGetCombosInfoResponse, error)
+	StopLists(ctx context.Context, req *StopListsRequest, opts ...Option) (*StopListsResponse, error)
+	PaymentTypes(ctx context.Context, req *PaymentTypesRequest, opts ...Option) (*PaymentTypesResponse, error)
+	DeliveriesOrderTypes(ctx context.Context, req *DeliveriesOrderTypesRequest, opts ...Option) (*DeliveriesOrderTypesResponse, error)
+	CancelCauses(ctx context.Context, req *CancelCausesRequest, opts ...Option) (*CancelCausesResponse, error)
+	Discounts(ctx context.Context, req *DiscountsRequest, opts ...Option) (*DiscountsResponse, error)
+	TipsTypes(ctx context.Context, req *TipsTypesRequest, opts ...Option) (*TipsTypesResponse, error)
+	RemovalTypes(ctx context.Context, req *RemovalTypesRequest, opts ...Option) (*RemovalTypesResponse, error)
+	Cities(ctx context.Context, req *CitiesRequest, opts ...Option) (*CitiesResponse, error)
+}
+
+// DeliveriesAPI is the part of the iikoCloud API that creates and tracks
+// deliveries and table orders.
+type DeliveriesAPI interface {
+	DeliveryCreate(ctx context.Context, req *DeliveryCreateRequest, opts ...Option) (*DeliveryCreateResponse, error)
+	DeliveriesByID(ctx context.Context, req *DeliveriesByIDRequest, opts ...Option) (*DeliveriesByIDResponse, error)
+	UpdateOrderDeliveryStatus(ctx context.Context, req *UpdateOrderDeliveryStatusRequest, opts ...Option) (*UpdateOrderDeliveryStatusResponse, error)
+	OrderCreate(ctx context.Context, req *OrderCreateRequest, opts ...Option) (*OrderCreateResponse, error)
+	CommandsStatus(ctx context.Context, req *CommandsStatusRequest, opts ...Option) (*CommandsStatusResponse, error)
+	NotificationsSend(ctx context.Context, req *NotificationsSendRequest, opts ...Option) (*NotificationsSendResponse, error)
+}
+
+// LoyaltyAPI is the part of the iikoCloud API that manages iikoCard customers.
+type LoyaltyAPI interface {
+	CustomerInfo(ctx context.Context, req *CustomerInfoRequest, opts ...Option) (*CustomerInfoResponse, error)
+	CreateOrUpdate(ctx context.Context, req *CreateOrUpdateRequest, opts ...Option) (*CreateOrUpdateResponse, error)
+	CardAdd(ctx context.Context, req *CardAddRequest, opts ...Option) (*CardAddResponse, error)
+	CustomerCategories(ctx context.Context, req *CustomerCategoriesRequest, opts ...Option) (*CustomerCategoriesResponse, error)
+	CustomerCategoryAdd(ctx context.Context, req *CustomerCategoryAddRequest, opts ...Option) error
+	CustomerCategoryRemove(ctx context.Context, req *CustomerCategoryRemoveRequest, opts ...Option) error
+	DeleteCustomers(ctx context.Context, req *DeleteCustomersRequest, opts ...Option) (*DeleteCustomersResponse, error)
+	RestoreCustomers(ctx context.Context, req *RestoreCustomersRequest, opts ...Option) (*RestoreCustomersResponse, error)
+	GetPrograms(ctx context.Context, req *GetProgramsRequest, opts ...Option) (*GetProgramsResponse, error)
+}
+
+// WebhooksAPI is the part of the iikoCloud API that configures webhooks.
+type WebhooksAPI interface {
+	WebhookSettings(ctx context.Context, req *WebhookSettingsRequest, opts ...Option) (*WebhookSettingsResponse, error)
+	WebhookUpdateSettings(ctx context.Context, req *WebhookUpdateSettingsRequest, opts ...Option) (*WebhookUpdateSettingsResponse, error)
+}
+
+// API is every iikoCloud API method the library covers. *Client and
+// *CachedClient implement it; iikomock.Mock is a programmable fake of it.
+//
+// Depend on API, or on the narrowest of CatalogAPI, DeliveriesAPI,
+// LoyaltyAPI and WebhooksAPI, instead of *Client to substitute the client in tests.
+type API interface {
+	CatalogAPI
+	DeliveriesAPI
+	LoyaltyAPI
+	WebhooksAPI
+}
+
+var (
+	_ API = (*Client)(nil)
+	_ API = (*CachedClient)(nil)
+)
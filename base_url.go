@@ -0,0 +1,141 @@
+package iiko
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Region is an iikoCloud installation. Every apiLogin belongs to exactly one.
+type Region string
+
+const (
+	RegionRU Region = "ru"
+	RegionEU Region = "eu"
+)
+
+// BaseURLEU is the base URL of iikoCloud API in the EU region.
+const BaseURLEU string = "https://api-eu.iiko.services"
+
+// DefaultFailoverCooldown is how long a base URL that failed is skipped by
+// the Client before it is tried again.
+const DefaultFailoverCooldown = 30 * time.Second
+
+// BaseURL returns the base URL of iikoCloud API in the region.
+// Unknown regions resolve to the RU one.
+func (r Region) BaseURL() string {
+	switch r {
+	case RegionEU:
+		return BaseURLEU
+	default:
+		return BaseURL
+	}
+}
+
+// WithBaseURL points the Client at a custom iikoCloud base URL, e.g. a local
+// stand-in server in tests.
+func WithBaseURL(url string) ClientOption {
+	return func(c *Client) {
+		c.baseURLs = newBaseURLPool(DefaultFailoverCooldown, url)
+	}
+}
+
+// WithRegion points the Client at the iikoCloud API of region r.
+func WithRegion(r Region) ClientOption {
+	return WithBaseURL(r.BaseURL())
+}
+
+// WithFailoverBaseURLs sets an ordered list of base URLs. The Client uses the
+// first healthy one; a URL that answers with a network error or a 5xx is
+// skipped for cooldown. Failover takes effect from the next attempt, so pair
+// it with WithRetryPolicy to fail over within a single call.
+//
+// Empty URLs are ignored; if none are left, the Client keeps its base URL.
+func WithFailoverBaseURLs(cooldown time.Duration, urls ...string) ClientOption {
+	return func(c *Client) {
+		var nonEmpty []string
+		for _, u := range urls {
+			if u != "" {
+				nonEmpty = append(nonEmpty, u)
+			}
+		}
+		if len(nonEmpty) == 0 {
+			return
+		}
+		c.baseURLs = newBaseURLPool(cooldown, nonEmpty...)
+	}
+}
+
+// BaseURL returns the base URL the next request will be sent to.
+func (c *Client) BaseURL() string {
+	return c.baseURLs.pick()
+}
+
+// baseURLPool keeps the health state of every configured base URL.
+type baseURLPool struct {
+	urls     []string
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	downUntil []time.Time
+}
+
+// newBaseURLPool returns a pool of urls, or of BaseURL if there are none.
+func newBaseURLPool(cooldown time.Duration, urls ...string) *baseURLPool {
+	if len(urls) == 0 {
+		urls = []string{BaseURL}
+	}
+
+	trimmed := make([]string, len(urls))
+	for i, u := range urls {
+		trimmed[i] = strings.TrimRight(u, "/")
+	}
+
+	return &baseURLPool{
+		urls:      trimmed,
+		cooldown:  cooldown,
+		downUntil: make([]time.Time, len(urls)),
+	}
+}
+
+// pick returns the first healthy URL. When all of them are down it returns
+// the one that recovers first.
+func (p *baseURLPool) pick() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	best := 0
+	for i, until := range p.downUntil {
+		if !now.Before(until) {
+			return p.urls[i]
+		}
+		if until.Before(p.downUntil[best]) {
+			best = i
+		}
+	}
+
+	return p.urls[best]
+}
+
+// report records the outcome of a request sent to url.
+func (p *baseURLPool) report(url string, healthy bool) {
+	if len(p.urls) < 2 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, u := range p.urls {
+		if u != url {
+			continue
+		}
+		if healthy {
+			p.downUntil[i] = time.Time{}
+		} else {
+			p.downUntil[i] = time.Now().Add(p.cooldown)
+		}
+		return
+	}
+}
@@ -0,0 +1,129 @@
+package iiko
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRegionBaseURL(t *testing.T) {
+	tests := []struct {
+		region Region
+		want   string
+	}{
+		{RegionRU, BaseURL},
+		{RegionEU, BaseURLEU},
+		{"", BaseURL},
+		{"us", BaseURL},
+	}
+	for _, tt := range tests {
+		if got := tt.region.BaseURL(); got != tt.want {
+			t.Errorf("Region(%q).BaseURL() = %q, want %q", tt.region, got, tt.want)
+		}
+	}
+}
+
+func TestBaseURLOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want string
+	}{
+		{"default", nil, BaseURL},
+		{"region", []ClientOption{WithRegion(RegionEU)}, BaseURLEU},
+		{"trailing slash", []ClientOption{WithBaseURL("http://local/")}, "http://local"},
+		{"failover", []ClientOption{WithFailoverBaseURLs(time.Second, "http://a", "http://b")}, "http://a"},
+		{"failover without urls", []ClientOption{WithFailoverBaseURLs(time.Second)}, BaseURL},
+		{"failover without urls keeps base url", []ClientOption{WithBaseURL("http://local"), WithFailoverBaseURLs(time.Second)}, "http://local"},
+		{"failover skips empty urls", []ClientOption{WithFailoverBaseURLs(time.Second, "", "http://b")}, "http://b"},
+		{"failover with empty urls only", []ClientOption{WithFailoverBaseURLs(time.Second, "", "")}, BaseURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient("test", append(tt.opts, WithLazyInit())...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			if got := c.BaseURL(); got != tt.want {
+				t.Errorf("BaseURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseURLPool(t *testing.T) {
+	type step struct {
+		report  string
+		healthy bool
+		want    string
+	}
+	tests := []struct {
+		name  string
+		urls  []string
+		steps []step
+	}{
+		{"empty falls back to BaseURL", nil, []step{
+			{"", true, BaseURL},
+		}},
+		{"single url is never marked down", []string{"a"}, []step{
+			{"a", false, "a"},
+		}},
+		{"fails over and back", []string{"a", "b"}, []step{
+			{"a", false, "b"},
+			{"a", true, "a"},
+		}},
+		{"all down picks first to recover", []string{"a", "b", "c"}, []step{
+			{"b", false, "a"},
+			{"a", false, "c"},
+			{"c", false, "b"},
+		}},
+		{"unknown url is ignored", []string{"a", "b"}, []step{
+			{"x", false, "a"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newBaseURLPool(time.Hour, tt.urls...)
+			for i, s := range tt.steps {
+				if s.report != "" {
+					p.report(s.report, s.healthy)
+				}
+				if got := p.pick(); got != s.want {
+					t.Fatalf("step %d: pick() = %q, want %q", i, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBaseURLFailover(t *testing.T) {
+	down := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	up := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+	})
+
+	c, err := NewClient("test",
+		WithFailoverBaseURLs(time.Hour, down.URL, up.URL),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2}),
+		WithLazyInit(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err := c.Organizations(context.Background(), &OrganizationsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := up.callCount("/api/1/organizations"); got != 1 {
+		t.Errorf("calls to the healthy URL = %d, want 1", got)
+	}
+	if got := c.BaseURL(); got != up.URL {
+		t.Errorf("BaseURL() = %q, want %q", got, up.URL)
+	}
+}
@@ -0,0 +1,335 @@
+package iiko
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CacheStore keeps cached API responses. Values are JSON encoded responses.
+//
+// MemoryCacheStore is shipped with the package; a Redis backend maps Set onto
+// SET PX and DeletePrefix onto SCAN MATCH prefix* followed by DEL.
+type CacheStore interface {
+	// Get returns the value stored under key, or nil if there is none or it has expired.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// DeletePrefix removes every value whose key starts with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// DefaultCacheTTLs are the time-to-live values of responses cached by CachedClient.
+var DefaultCacheTTLs = map[string]time.Duration{
+	"/api/1/payment_types":          15 * time.Minute,
+	"/api/1/deliveries/order_types": 15 * time.Minute,
+	"/api/1/cancel_causes":          time.Hour,
+	"/api/1/discounts":              15 * time.Minute,
+	"/api/1/tips_types":             time.Hour,
+	"/api/1/removal_types":          time.Hour,
+	"/api/1/cities":                 6 * time.Hour,
+	"/api/1/terminal_groups":        15 * time.Minute,
+}
+
+// cacheInvalidations lists the endpoints whose cached responses a webhook event makes stale.
+var cacheInvalidations = map[WebhookEventType][]string{
+	NomenclatureUpdateWebhookEvent: {
+		"/api/1/payment_types",
+		"/api/1/deliveries/order_types",
+		"/api/1/cancel_causes",
+		"/api/1/discounts",
+		"/api/1/tips_types",
+		"/api/1/removal_types",
+		"/api/1/cities",
+	},
+	BusinessHoursAndMappingUpdateWebhookEvent: {
+		"/api/1/terminal_groups",
+		"/api/1/cities",
+	},
+}
+
+// cacheAnyOrganization is the organization part of cache keys of requests for
+// several organizations, or for none.
+const cacheAnyOrganization = "*"
+
+// CachedClient is a Client that caches responses of dictionary endpoints:
+// payment types, order types, cancel causes, discounts, tips types, removal
+// types, cities and terminal groups. All other methods are the Client's own.
+//
+// Responses are cached per organization, so that an update of one
+// organization keeps the responses of the others. Calls with per-call options
+// bypass the cache.
+type CachedClient struct {
+	*Client
+
+	store  CacheStore
+	ttls   map[string]time.Duration
+	prefix string
+}
+
+// NewCachedClient wraps c into a CachedClient storing responses in store, or in
+// a new MemoryCacheStore if store is nil. ttls override DefaultCacheTTLs per
+// endpoint; a zero TTL disables caching of an endpoint.
+func NewCachedClient(c *Client, store CacheStore, ttls map[string]time.Duration) *CachedClient {
+	if store == nil {
+		mem := NewMemoryCacheStore()
+		mem.clock = c.clock
+		store = mem
+	}
+
+	merged := make(map[string]time.Duration, len(DefaultCacheTTLs))
+	for endpoint, ttl := range DefaultCacheTTLs {
+		merged[endpoint] = ttl
+	}
+	for endpoint, ttl := range ttls {
+		merged[endpoint] = ttl
+	}
+
+	sum := sha256.Sum256([]byte(c.apiLogin + "\x00" + c.appId))
+	return &CachedClient{
+		Client: c,
+		store:  store,
+		ttls:   merged,
+		prefix: "iiko-cache-" + hex.EncodeToString(sum[:16]) + ":",
+	}
+}
+
+// Invalidate drops cached responses of the given endpoints, or of every
+// endpoint if none are given.
+func (c *CachedClient) Invalidate(ctx context.Context, endpoints ...string) error {
+	if len(endpoints) == 0 {
+		return c.store.DeletePrefix(ctx, c.prefix)
+	}
+	for _, endpoint := range endpoints {
+		if err := c.store.DeletePrefix(ctx, c.endpointPrefix(endpoint)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidateOrganization drops cached responses of the given endpoints, or of
+// every endpoint if none are given, that may hold data of organization id.
+// Responses to requests for several organizations are dropped if they may
+// include it.
+func (c *CachedClient) InvalidateOrganization(ctx context.Context, id uuid.UUID, endpoints ...string) error {
+	if len(endpoints) == 0 {
+		endpoints = make([]string, 0, len(c.ttls))
+		for endpoint := range c.ttls {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	for _, endpoint := range endpoints {
+		for _, org := range []string{id.String(), cacheAnyOrganization} {
+			if err := c.store.DeletePrefix(ctx, c.endpointPrefix(endpoint)+org+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// InvalidateOnWebhooks registers handlers on s that drop cached responses made
+// stale by NomenclatureUpdate and BusinessHoursAndMappingUpdate events, for
+// the organization of the event only.
+func (c *CachedClient) InvalidateOnWebhooks(s *WebhookServer) {
+	for eventType, endpoints := range cacheInvalidations {
+		endpoints := endpoints
+		s.RegisterHandler(eventType, "iiko cache invalidation", func(ctx context.Context, event *WebhookEvent) error {
+			if event.OrganizationID == uuid.Nil {
+				return c.Invalidate(ctx, endpoints...)
+			}
+			return c.InvalidateOrganization(ctx, event.OrganizationID, endpoints...)
+		})
+	}
+}
+
+func (c *CachedClient) endpointPrefix(endpoint string) string {
+	return c.prefix + endpoint + "\n"
+}
+
+// cacheOrganization returns the organization part of the cache key of a
+// request body: the ID of the only organization it is for, or
+// cacheAnyOrganization.
+func cacheOrganization(body []byte) string {
+	var v struct {
+		OrganizationID  *uuid.UUID  `json:"organizationId"`
+		OrganizationIDs []uuid.UUID `json:"organizationIds"`
+	}
+	if json.Unmarshal(body, &v) != nil {
+		return cacheAnyOrganization
+	}
+
+	switch {
+	case v.OrganizationID != nil && len(v.OrganizationIDs) == 0:
+		return v.OrganizationID.String()
+	case v.OrganizationID == nil && len(v.OrganizationIDs) == 1:
+		return v.OrganizationIDs[0].String()
+	default:
+		return cacheAnyOrganization
+	}
+}
+
+// PaymentTypes is Client.PaymentTypes served from the cache when possible.
+func (c *CachedClient) PaymentTypes(ctx context.Context, req *PaymentTypesRequest, opts ...Option) (*PaymentTypesResponse, error) {
+	return cachedCall(ctx, c, paymentTypesEndpoint, req, opts...)
+}
+
+// DeliveriesOrderTypes is Client.DeliveriesOrderTypes served from the cache when possible.
+func (c *CachedClient) DeliveriesOrderTypes(ctx context.Context, req *DeliveriesOrderTypesRequest, opts ...Option) (*DeliveriesOrderTypesResponse, error) {
+	return cachedCall(ctx, c, deliveriesOrderTypesEndpoint, req, opts...)
+}
+
+// CancelCauses is Client.CancelCauses served from the cache when possible.
+func (c *CachedClient) CancelCauses(ctx context.Context, req *CancelCausesRequest, opts ...Option) (*CancelCausesResponse, error) {
+	return cachedCall(ctx, c, cancelCausesEndpoint, req, opts...)
+}
+
+// Discounts is Client.Discounts served from the cache when possible.
+func (c *CachedClient) Discounts(ctx context.Context, req *DiscountsRequest, opts ...Option) (*DiscountsResponse, error) {
+	return cachedCall(ctx, c, discountsEndpoint, req, opts...)
+}
+
+// TipsTypes is Client.TipsTypes served from the cache when possible.
+func (c *CachedClient) TipsTypes(ctx context.Context, req *TipsTypesRequest, opts ...Option) (*TipsTypesResponse, error) {
+	return cachedCall(ctx, c, tipsTypesEndpoint, req, opts...)
+}
+
+// RemovalTypes is Client.RemovalTypes served from the cache when possible.
+func (c *CachedClient) RemovalTypes(ctx context.Context, req *RemovalTypesRequest, opts ...Option) (*RemovalTypesResponse, error) {
+	return cachedCall(ctx, c, removalTypesEndpoint, req, opts...)
+}
+
+// Cities is Client.Cities served from the cache when possible.
+func (c *CachedClient) Cities(ctx context.Context, req *CitiesRequest, opts ...Option) (*CitiesResponse, error) {
+	return cachedCall(ctx, c, citiesEndpoint, req, opts...)
+}
+
+// TerminalGroups is Client.TerminalGroups served from the cache when possible.
+func (c *CachedClient) TerminalGroups(ctx context.Context, req *TerminalGroupsRequest, opts ...Option) (*TerminalGroupsResponse, error) {
+	return cachedCall(ctx, c, terminalGroupsEndpoint, req, opts...)
+}
+
+// cachedCall serves a call to e from the cache, or performs it and caches the
+// response. Cache failures never fail the call; they are logged, if the Client
+// has a logger.
+func cachedCall[Req, Resp any](ctx context.Context, c *CachedClient, e *Endpoint[Req, Resp], req *Req, opts ...Option) (*Resp, error) {
+	endpoint := e.Info().Path
+	ttl := c.ttls[endpoint]
+	if ttl <= 0 || len(opts) > 0 {
+		return e.Call(ctx, c.Client, req, opts...)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := coalesceKey(endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	key := c.endpointPrefix(endpoint) + cacheOrganization(body) + "\n" + hex.EncodeToString(sum[:])
+
+	cached, err := c.store.Get(ctx, key)
+	if err != nil {
+		c.logCacheError(ctx, endpoint, err)
+	} else if cached != nil {
+		var response Resp
+		if err := json.Unmarshal(cached, &response); err == nil {
+			return &response, nil
+		}
+	}
+
+	response, err := e.Call(ctx, c.Client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, err := json.Marshal(response); err == nil {
+		if err := c.store.Set(ctx, key, value, ttl); err != nil {
+			c.logCacheError(ctx, endpoint, err)
+		}
+	}
+
+	return response, nil
+}
+
+func (c *CachedClient) logCacheError(ctx context.Context, endpoint string, err error) {
+	if c.logger != nil {
+		c.logger.WarnContext(ctx, "iiko cache failed", "endpoint", endpoint, "error", err)
+	}
+}
+
+// MemoryCacheStore is a CacheStore local to one process.
+type MemoryCacheStore struct {
+	clock Clock
+
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	value []byte
+	until time.Time
+}
+
+// NewMemoryCacheStore creates an empty MemoryCacheStore.
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{
+		clock:   realClock{},
+		entries: make(map[string]memoryCacheEntry),
+	}
+}
+
+// Get implements CacheStore.
+func (s *MemoryCacheStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if !s.clock.Now().Before(entry.until) {
+		delete(s.entries, key)
+		return nil, nil
+	}
+	return entry.value, nil
+}
+
+// Set implements CacheStore.
+func (s *MemoryCacheStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	for k, entry := range s.entries {
+		if !now.Before(entry.until) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = memoryCacheEntry{value: value, until: now.Add(ttl)}
+	return nil
+}
+
+// DeletePrefix implements CacheStore.
+func (s *MemoryCacheStore) DeletePrefix(_ context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
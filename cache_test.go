@@ -0,0 +1,235 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	testOrgA = uuid.MustParse("7bc05553-4b68-44e8-b7bc-37be63c6d9e9")
+	testOrgB = uuid.MustParse("2e8a5d1c-1f2e-4c3b-9a4d-5e6f7a8b9c0d")
+)
+
+func TestMemoryCacheStore(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	s := NewMemoryCacheStore()
+	s.clock = clock
+
+	_ = s.Set(ctx, "a:1", []byte("1"), time.Hour)
+	_ = s.Set(ctx, "a:2", []byte("2"), time.Hour)
+	_ = s.Set(ctx, "b:1", []byte("3"), time.Hour)
+	_ = s.Set(ctx, "short", []byte("4"), time.Millisecond)
+	clock.Advance(time.Millisecond)
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"a:1", "1"},
+		{"b:1", "3"},
+		{"short", ""},
+		{"missing", ""},
+	}
+	for _, tt := range tests {
+		if got, err := s.Get(ctx, tt.key); err != nil || string(got) != tt.want {
+			t.Errorf("Get(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+		}
+	}
+
+	_ = s.DeletePrefix(ctx, "a:")
+	for key, want := range map[string]string{"a:1": "", "a:2": "", "b:1": "3"} {
+		if got, _ := s.Get(ctx, key); string(got) != want {
+			t.Errorf("after DeletePrefix: Get(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestCacheOrganization(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"one of organizationIds", `{"organizationIds":["` + testOrgA.String() + `"]}`, testOrgA.String()},
+		{"organizationId", `{"organizationId":"` + testOrgB.String() + `"}`, testOrgB.String()},
+		{"upper case", `{"organizationIds":["7BC05553-4B68-44E8-B7BC-37BE63C6D9E9"]}`, testOrgA.String()},
+		{"several", `{"organizationIds":["` + testOrgA.String() + `","` + testOrgB.String() + `"]}`, cacheAnyOrganization},
+		{"none", `{}`, cacheAnyOrganization},
+		{"invalid id", `{"organizationIds":["x"]}`, cacheAnyOrganization},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cacheOrganization([]byte(tt.body)); got != tt.want {
+				t.Errorf("cacheOrganization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newCachedTestClient returns a CachedClient whose server answers every call
+// with an empty dictionary.
+func newCachedTestClient(t *testing.T, ttls map[string]time.Duration) (*CachedClient, *testServer) {
+	t.Helper()
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+	})
+	return NewCachedClient(newTestClient(t, srv), nil, ttls), srv
+}
+
+func TestCachedClientCalls(t *testing.T) {
+	ctx := context.Background()
+	reqA := &PaymentTypesRequest{OrganizationIDs: []uuid.UUID{testOrgA}}
+
+	tests := []struct {
+		name      string
+		ttls      map[string]time.Duration
+		opts      []Option
+		wantCalls int
+	}{
+		{"cached", nil, nil, 1},
+		{"disabled by zero ttl", map[string]time.Duration{"/api/1/payment_types": 0}, nil, 3},
+		{"bypassed with options", nil, []Option{WithCustomTimeout(time.Second)}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, srv := newCachedTestClient(t, tt.ttls)
+			for i := 0; i < 3; i++ {
+				if _, err := c.PaymentTypes(ctx, reqA, tt.opts...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := srv.callCount("/api/1/payment_types"); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCachedClientWebhookInvalidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    WebhookEventType
+		org      uuid.UUID
+		endpoint string
+		// wantRefetched lists which cached requests are sent again: for A, for B, for both.
+		wantRefetched [3]bool
+	}{
+		{"nomenclature of A", NomenclatureUpdateWebhookEvent, testOrgA, "/api/1/payment_types", [3]bool{true, false, true}},
+		{"nomenclature of B", NomenclatureUpdateWebhookEvent, testOrgB, "/api/1/payment_types", [3]bool{false, true, true}},
+		{"mapping of A", BusinessHoursAndMappingUpdateWebhookEvent, testOrgA, "/api/1/terminal_groups", [3]bool{true, false, true}},
+		{"cities on nomenclature", NomenclatureUpdateWebhookEvent, testOrgA, "/api/1/cities", [3]bool{true, false, true}},
+		{"cities on mapping", BusinessHoursAndMappingUpdateWebhookEvent, testOrgB, "/api/1/cities", [3]bool{false, true, true}},
+		{"mapping keeps payment types", BusinessHoursAndMappingUpdateWebhookEvent, testOrgA, "/api/1/payment_types", [3]bool{false, false, false}},
+		{"event without organization", NomenclatureUpdateWebhookEvent, uuid.Nil, "/api/1/payment_types", [3]bool{true, true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c, srv := newCachedTestClient(t, nil)
+			s := NewWebhookServer("secret")
+			c.InvalidateOnWebhooks(s)
+
+			orgs := [][]uuid.UUID{{testOrgA}, {testOrgB}, {testOrgA, testOrgB}}
+			call := func(ids []uuid.UUID) {
+				var err error
+				switch tt.endpoint {
+				case "/api/1/payment_types":
+					_, err = c.PaymentTypes(ctx, &PaymentTypesRequest{OrganizationIDs: ids})
+				case "/api/1/terminal_groups":
+					_, err = c.TerminalGroups(ctx, &TerminalGroupsRequest{OrganizationIDs: ids})
+				case "/api/1/cities":
+					_, err = c.Cities(ctx, &CitiesRequest{OrganizationIDs: ids})
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, ids := range orgs {
+				call(ids)
+			}
+
+			if err := s.HandleEvent(ctx, &WebhookEvent{EventType: tt.event, OrganizationID: tt.org}, "secret"); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, ids := range orgs {
+				before := srv.callCount(tt.endpoint)
+				call(ids)
+				if refetched := srv.callCount(tt.endpoint) > before; refetched != tt.wantRefetched[i] {
+					t.Errorf("request %d: refetched %v, want %v", i, refetched, tt.wantRefetched[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCachedClientInvalidate(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newCachedTestClient(t, nil)
+
+	call := func() {
+		if _, err := c.PaymentTypes(ctx, &PaymentTypesRequest{OrganizationIDs: []uuid.UUID{testOrgA}}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Discounts(ctx, &DiscountsRequest{OrganizationIDs: []uuid.UUID{testOrgA}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	call()
+
+	tests := []struct {
+		name       string
+		invalidate func() error
+		wantCalls  int
+	}{
+		{"one endpoint", func() error { return c.Invalidate(ctx, "/api/1/payment_types") }, 2},
+		{"everything", func() error { return c.Invalidate(ctx) }, 3},
+		{"organization", func() error { return c.InvalidateOrganization(ctx, testOrgA) }, 4},
+		{"other organization", func() error { return c.InvalidateOrganization(ctx, testOrgB) }, 4},
+	}
+	for _, tt := range tests {
+		if err := tt.invalidate(); err != nil {
+			t.Fatal(err)
+		}
+		call()
+		if got := srv.callCount("/api/1/payment_types"); got != tt.wantCalls {
+			t.Errorf("%s: payment types calls = %d, want %d", tt.name, got, tt.wantCalls)
+		}
+	}
+}
+
+// failingCacheStore fails every operation.
+type failingCacheStore struct{}
+
+var errCacheDown = errors.New("cache is down")
+
+func (failingCacheStore) Get(context.Context, string) ([]byte, error) { return nil, errCacheDown }
+func (failingCacheStore) Set(context.Context, string, []byte, time.Duration) error {
+	return errCacheDown
+}
+func (failingCacheStore) DeletePrefix(context.Context, string) error { return errCacheDown }
+
+func TestCachedClientStoreFailure(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+	})
+	c := NewCachedClient(newTestClient(t, srv), failingCacheStore{}, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.PaymentTypes(context.Background(), &PaymentTypesRequest{OrganizationIDs: []uuid.UUID{testOrgA}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := srv.callCount("/api/1/payment_types"); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+	if err := c.InvalidateOrganization(context.Background(), testOrgA); !errors.Is(err, errCacheDown) {
+		t.Errorf("InvalidateOrganization = %v, want the store error", err)
+	}
+}
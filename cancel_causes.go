@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -29,15 +31,11 @@ type CancelCausesResponse struct {
 	CancelCauses []CancelCauses `json:"cancelCauses"`
 }
 
+var cancelCausesEndpoint = NewEndpoint[CancelCausesRequest, CancelCausesResponse](EndpointInfo{Path: "/api/1/cancel_causes", RequiresAuth: true, Idempotent: true})
+
 // Delivery cancel causes. Allowed from version 7.7.1.
 //
 // iiko API: /api/1/cancel_causes
-func (c *Client) CancelCauses(req *CancelCausesRequest, opts ...Option) (*CancelCausesResponse, error) {
-	var response CancelCausesResponse
-
-	if err := c.post(true, "/api/1/cancel_causes", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) CancelCauses(ctx context.Context, req *CancelCausesRequest, opts ...Option) (*CancelCausesResponse, error) {
+	return cancelCausesEndpoint.Call(ctx, c, req, opts...)
 }
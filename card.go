@@ -4,5 +4,5 @@ type Card struct {
 	Id          string `json:"id"`
 	Track       string `json:"track"`
 	Number      string `json:"number"`
-	ValidToDate string `json:"validToDate"`
+	ValidToDate Time   `json:"validToDate"`
 }
@@ -1,5 +1,7 @@
 package iiko
 
+import "context"
+
 type CardAddRequest struct {
 	// uuid
 	CustomerId string `json:"customerId"`
@@ -11,15 +13,11 @@ type CardAddRequest struct {
 
 type CardAddResponse struct{}
 
+var cardAddEndpoint = NewEndpoint[CardAddRequest, CardAddResponse](EndpointInfo{Path: "/api/1/loyalty/iiko/customer/card/add", RequiresAuth: true})
+
 // CardAdd Add new card for customer
 //
 // iiko API: /api/1/loyalty/iiko/customer/card/add
-func (c *Client) CardAdd(req *CardAddRequest, opts ...Option) (*CardAddResponse, error) {
-	var response CardAddResponse
-
-	if err := c.post(true, "/api/1/loyalty/iiko/customer/card/add", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) CardAdd(ctx context.Context, req *CardAddRequest, opts ...Option) (*CardAddResponse, error) {
+	return cardAddEndpoint.Call(ctx, c, req, opts...)
 }
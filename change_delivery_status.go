@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -11,7 +13,7 @@ type UpdateOrderDeliveryStatusRequest struct {
 	// Order ID [required]
 	OrderId uuid.UUID `json:"orderId"`
 	// Delivery date [optional]
-	DeliveryDate string `json:"deliveryDate,omitempty"`
+	DeliveryDate *Time `json:"deliveryDate,omitempty"`
 	// Delivery status [required]
 	Status DeliveryStatus `json:"deliveryStatus"`
 }
@@ -21,15 +23,11 @@ type UpdateOrderDeliveryStatusResponse struct {
 	CorrelationId uuid.UUID `json:"correlationId"`
 }
 
+var updateOrderDeliveryStatusEndpoint = NewEndpoint[UpdateOrderDeliveryStatusRequest, UpdateOrderDeliveryStatusResponse](EndpointInfo{Path: "/api/1/deliveries/update_order_delivery_status", RequiresAuth: true})
+
 // UpdateOrderDeliveryStatus Update order delivery status
 //
 // iiko API: /api/1/deliveries/update_order_delivery_status
-func (c *Client) UpdateOrderDeliveryStatus(req *UpdateOrderDeliveryStatusRequest, opts ...Option) (*UpdateOrderDeliveryStatusResponse, error) {
-	var response UpdateOrderDeliveryStatusResponse
-
-	if err := c.post(true, "/api/1/deliveries/update_order_delivery_status", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) UpdateOrderDeliveryStatus(ctx context.Context, req *UpdateOrderDeliveryStatusRequest, opts ...Option) (*UpdateOrderDeliveryStatusResponse, error) {
+	return updateOrderDeliveryStatusEndpoint.Call(ctx, c, req, opts...)
 }
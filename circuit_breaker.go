@@ -0,0 +1,342 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// breakerIdleTTL is how long a closed circuit that counted some failures is
+// kept without calls before it is forgotten.
+const breakerIdleTTL = 10 * time.Minute
+
+// ErrCircuitOpen matches, with errors.Is, calls rejected by the circuit breaker.
+var ErrCircuitOpen = errors.New("iiko: circuit breaker is open")
+
+// BreakerState is the state of one circuit of the circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed: calls go through and failures are counted.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen: calls fail fast with *CircuitOpenError.
+	BreakerOpen
+	// BreakerHalfOpen: a limited number of probe calls go through to check
+	// whether iiko has recovered.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerKey identifies a circuit: an endpoint called for an organization and
+// terminal group. IDs are empty for requests that don't carry them.
+type BreakerKey struct {
+	Endpoint        string
+	OrganizationID  string
+	TerminalGroupID string
+}
+
+// CircuitBreakerSettings configures WithCircuitBreaker. Zero values are
+// replaced by defaults.
+type CircuitBreakerSettings struct {
+	// Number of consecutive failures that opens a circuit. Default 5.
+	FailureThreshold int
+
+	// How long a circuit stays open before probe calls are let through. Default 30s.
+	OpenTimeout time.Duration
+
+	// Number of concurrent probe calls in the half-open state. Default 1.
+	HalfOpenMaxCalls int
+
+	// IsFailure reports whether the result of a call counts as a failure.
+	// If nil, transport errors, timeouts and 5xx responses do; iiko API errors
+	// caused by the request itself (4xx) don't.
+	IsFailure func(err error) bool
+
+	// OnStateChange, if set, is called on every state transition of a circuit.
+	// It must not block.
+	OnStateChange func(key BreakerKey, from, to BreakerState)
+}
+
+// CircuitOpenError is returned without calling iiko while the circuit of a call is open.
+type CircuitOpenError struct {
+	Key BreakerKey
+	// Time the circuit lets the next probe call through.
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("iiko: %s: circuit breaker is open until %s", e.Key.Endpoint, e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// WithCircuitBreaker makes the Client fail fast on endpoints that keep failing
+// for an organization or terminal group, instead of waiting the full timeout
+// on every call. Retries of one call count as a single result.
+func WithCircuitBreaker(s CircuitBreakerSettings) ClientOption {
+	if s.FailureThreshold <= 0 {
+		s.FailureThreshold = 5
+	}
+	if s.OpenTimeout <= 0 {
+		s.OpenTimeout = 30 * time.Second
+	}
+	if s.HalfOpenMaxCalls <= 0 {
+		s.HalfOpenMaxCalls = 1
+	}
+	if s.IsFailure == nil {
+		s.IsFailure = isBreakerFailure
+	}
+
+	return func(c *Client) {
+		c.breaker = &circuitBreaker{
+			settings: s,
+			circuits: make(map[BreakerKey]*circuit),
+		}
+	}
+}
+
+// CircuitState returns the state of the circuit identified by key.
+// It is BreakerClosed if the Client has no circuit breaker.
+func (c *Client) CircuitState(key BreakerKey) BreakerState {
+	if c.breaker == nil {
+		return BreakerClosed
+	}
+	return c.breaker.state(key, c.clock.Now())
+}
+
+func isBreakerFailure(err error) bool {
+	if err == nil || errors.Is(err, ErrRateLimitWait) {
+		return false
+	}
+	var transportErr *TransportError
+	return errors.As(err, &transportErr) || errors.Is(err, ErrTimeout) || errors.Is(err, ErrServerError)
+}
+
+type circuit struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probes   int
+	// lastUsed is when the circuit last admitted a call or recorded a result.
+	lastUsed time.Time
+	// generation changes with every state change, so that results of calls
+	// admitted in an earlier state are not taken for those of the current one.
+	generation uint64
+	// inflight counts admitted calls whose result is not recorded yet; the
+	// circuit is kept while there are any.
+	inflight int
+}
+
+// admission is a call admitted by allow, stamped with the generation of its
+// circuit at that time.
+type admission struct {
+	generation uint64
+}
+
+// circuitBreaker keeps a circuit per BreakerKey. Since keys carry organization
+// and terminal group IDs, closed circuits are removed as soon as they hold no
+// failures, and the remaining closed ones once idle for breakerIdleTTL.
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+
+	mu        sync.Mutex
+	circuits  map[BreakerKey]*circuit
+	lastSweep time.Time
+}
+
+type breakerTransition struct {
+	key      BreakerKey
+	from, to BreakerState
+}
+
+// breakCircuits is the middleware that runs calls through the circuit breaker.
+func breakCircuits(b *circuitBreaker, clock Clock) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			ids := extractIDs(call.Body)
+			key := BreakerKey{
+				Endpoint:        call.Endpoint,
+				OrganizationID:  ids.organizationID,
+				TerminalGroupID: ids.terminalGroupID,
+			}
+
+			adm, err := b.allow(key, clock.Now())
+			if err != nil {
+				return err
+			}
+
+			err = next(ctx, call)
+			if errors.Is(err, context.Canceled) {
+				// The caller gave up; that says nothing about iiko.
+				b.release(key, adm)
+				return err
+			}
+			b.record(key, adm, b.settings.IsFailure(err), clock.Now())
+			return err
+		}
+	}
+}
+
+// allow admits a call or returns *CircuitOpenError.
+func (b *circuitBreaker) allow(key BreakerKey, now time.Time) (admission, error) {
+	b.mu.Lock()
+	b.sweep(now)
+	cb := b.circuit(key)
+	cb.lastUsed = now
+
+	var transitions []breakerTransition
+	if cb.state == BreakerOpen && !now.Before(cb.openedAt.Add(b.settings.OpenTimeout)) {
+		transitions = append(transitions, b.setState(key, cb, BreakerHalfOpen, now))
+	}
+
+	var err error
+	switch cb.state {
+	case BreakerOpen:
+		err = &CircuitOpenError{Key: key, RetryAt: cb.openedAt.Add(b.settings.OpenTimeout)}
+	case BreakerHalfOpen:
+		if cb.probes >= b.settings.HalfOpenMaxCalls {
+			err = &CircuitOpenError{Key: key, RetryAt: now}
+		} else {
+			cb.probes++
+		}
+	}
+	adm := admission{generation: cb.generation}
+	if err == nil {
+		cb.inflight++
+	}
+	b.mu.Unlock()
+
+	b.notify(transitions)
+	return adm, err
+}
+
+// record accounts the result of an admitted call. Results of calls admitted
+// before the last state change are ignored: e.g. a late success of a call
+// admitted while the circuit was closed is no half-open probe.
+func (b *circuitBreaker) record(key BreakerKey, adm admission, failed bool, now time.Time) {
+	b.mu.Lock()
+	cb := b.circuit(key)
+	cb.lastUsed = now
+	cb.inflight--
+
+	var transitions []breakerTransition
+	switch {
+	case adm.generation != cb.generation:
+	case cb.state == BreakerClosed:
+		if !failed {
+			cb.failures = 0
+		} else if cb.failures++; cb.failures >= b.settings.FailureThreshold {
+			transitions = append(transitions, b.setState(key, cb, BreakerOpen, now))
+		}
+	case cb.state == BreakerHalfOpen:
+		cb.probes--
+		if failed {
+			transitions = append(transitions, b.setState(key, cb, BreakerOpen, now))
+		} else {
+			transitions = append(transitions, b.setState(key, cb, BreakerClosed, now))
+		}
+	}
+	b.forgetIfClean(key, cb)
+	b.mu.Unlock()
+
+	b.notify(transitions)
+}
+
+// release frees the probe slot of an admitted call without accounting its result.
+func (b *circuitBreaker) release(key BreakerKey, adm admission) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb := b.circuit(key)
+	cb.inflight--
+	if cb.state == BreakerHalfOpen && adm.generation == cb.generation {
+		cb.probes--
+	}
+	b.forgetIfClean(key, cb)
+}
+
+func (b *circuitBreaker) state(key BreakerKey, now time.Time) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb, ok := b.circuits[key]
+	if !ok {
+		return BreakerClosed
+	}
+	if cb.state == BreakerOpen && !now.Before(cb.openedAt.Add(b.settings.OpenTimeout)) {
+		return BreakerHalfOpen
+	}
+	return cb.state
+}
+
+// circuit returns the circuit of key, creating a closed one. b.mu must be held.
+func (b *circuitBreaker) circuit(key BreakerKey) *circuit {
+	cb, ok := b.circuits[key]
+	if !ok {
+		cb = &circuit{}
+		b.circuits[key] = cb
+	}
+	return cb
+}
+
+// forgetIfClean removes cb if it is closed without failures or calls in
+// flight, i.e. no different from a circuit created anew. b.mu must be held.
+func (b *circuitBreaker) forgetIfClean(key BreakerKey, cb *circuit) {
+	if cb.state == BreakerClosed && cb.failures == 0 && cb.inflight == 0 {
+		delete(b.circuits, key)
+	}
+}
+
+// sweep removes closed circuits idle for breakerIdleTTL, at most once per
+// breakerIdleTTL. b.mu must be held.
+func (b *circuitBreaker) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < breakerIdleTTL {
+		return
+	}
+	b.lastSweep = now
+
+	for key, cb := range b.circuits {
+		if cb.state == BreakerClosed && cb.inflight == 0 && now.Sub(cb.lastUsed) >= breakerIdleTTL {
+			delete(b.circuits, key)
+		}
+	}
+}
+
+// setState moves cb to state. b.mu must be held.
+func (b *circuitBreaker) setState(key BreakerKey, cb *circuit, state BreakerState, now time.Time) breakerTransition {
+	t := breakerTransition{key: key, from: cb.state, to: state}
+
+	cb.state = state
+	cb.generation++
+	cb.failures = 0
+	cb.probes = 0
+	if state == BreakerOpen {
+		cb.openedAt = now
+	}
+	return t
+}
+
+func (b *circuitBreaker) notify(transitions []breakerTransition) {
+	if b.settings.OnStateChange == nil {
+		return
+	}
+	for _, t := range transitions {
+		b.settings.OnStateChange(t.key, t.from, t.to)
+	}
+}
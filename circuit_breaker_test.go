@@ -0,0 +1,305 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(s CircuitBreakerSettings) *circuitBreaker {
+	c := &Client{}
+	WithCircuitBreaker(s)(c)
+	return c.breaker
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+	key := BreakerKey{Endpoint: "/api/1/organizations"}
+
+	type step struct {
+		advance time.Duration
+		// result of the call if it is admitted: "ok", "fail" or "cancel".
+		result    string
+		wantAllow bool
+		wantState BreakerState
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"successes keep it closed", []step{
+			{0, "ok", true, BreakerClosed},
+			{0, "ok", true, BreakerClosed},
+		}},
+		{"opens at the threshold", []step{
+			{0, "fail", true, BreakerClosed},
+			{0, "fail", true, BreakerOpen},
+			{0, "ok", false, BreakerOpen},
+		}},
+		{"a success resets the count", []step{
+			{0, "fail", true, BreakerClosed},
+			{0, "ok", true, BreakerClosed},
+			{0, "fail", true, BreakerClosed},
+		}},
+		{"probe closes it", []step{
+			{0, "fail", true, BreakerClosed},
+			{0, "fail", true, BreakerOpen},
+			{time.Minute, "ok", true, BreakerClosed},
+		}},
+		{"failed probe reopens it", []step{
+			{0, "fail", true, BreakerClosed},
+			{0, "fail", true, BreakerOpen},
+			{time.Minute, "fail", true, BreakerOpen},
+			{time.Second, "ok", false, BreakerOpen},
+		}},
+		{"cancelled probe frees its slot", []step{
+			{0, "fail", true, BreakerClosed},
+			{0, "fail", true, BreakerOpen},
+			{time.Minute, "cancel", true, BreakerHalfOpen},
+			{0, "ok", true, BreakerClosed},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var transitions []string
+			b := newTestBreaker(CircuitBreakerSettings{
+				FailureThreshold: 2,
+				OpenTimeout:      time.Minute,
+				OnStateChange: func(_ BreakerKey, from, to BreakerState) {
+					transitions = append(transitions, from.String()+">"+to.String())
+				},
+			})
+			clock := newFakeClock()
+
+			for i, s := range tt.steps {
+				clock.Advance(s.advance)
+				adm, err := b.allow(key, clock.Now())
+				if (err == nil) != s.wantAllow {
+					t.Fatalf("step %d: allow = %v, want allowed %v", i, err, s.wantAllow)
+				}
+				if err == nil {
+					switch s.result {
+					case "cancel":
+						b.release(key, adm)
+					default:
+						b.record(key, adm, s.result == "fail", clock.Now())
+					}
+				} else if !errors.Is(err, ErrCircuitOpen) {
+					t.Fatalf("step %d: err = %v, want ErrCircuitOpen", i, err)
+				}
+				if got := b.state(key, clock.Now()); got != s.wantState {
+					t.Fatalf("step %d: state = %s, want %s (transitions %v)", i, got, s.wantState, transitions)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbes(t *testing.T) {
+	key := BreakerKey{Endpoint: "/api/1/organizations"}
+	b := newTestBreaker(CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenMaxCalls: 2})
+	clock := newFakeClock()
+
+	adm, _ := b.allow(key, clock.Now())
+	b.record(key, adm, true, clock.Now())
+	clock.Advance(time.Minute)
+
+	for i, want := range []bool{true, true, false} {
+		if _, err := b.allow(key, clock.Now()); (err == nil) != want {
+			t.Errorf("probe %d: allow = %v, want allowed %v", i, err, want)
+		}
+	}
+}
+
+func TestCircuitBreakerStaleResults(t *testing.T) {
+	key := BreakerKey{Endpoint: "/api/1/organizations"}
+
+	tests := []struct {
+		name string
+		// result of the call admitted while the circuit was closed, which
+		// finishes once the circuit is half-open.
+		result    string
+		wantState BreakerState
+	}{
+		{"late success is no probe", "ok", BreakerHalfOpen},
+		{"late failure is no probe", "fail", BreakerHalfOpen},
+		{"late cancel frees no probe slot", "cancel", BreakerHalfOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBreaker(CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenMaxCalls: 1})
+			clock := newFakeClock()
+
+			slow, err := b.allow(key, clock.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			failing, _ := b.allow(key, clock.Now())
+			b.record(key, failing, true, clock.Now())
+			clock.Advance(time.Minute)
+
+			probe, err := b.allow(key, clock.Now())
+			if err != nil {
+				t.Fatalf("probe: %v", err)
+			}
+			switch tt.result {
+			case "cancel":
+				b.release(key, slow)
+			default:
+				b.record(key, slow, tt.result == "fail", clock.Now())
+			}
+
+			if got := b.state(key, clock.Now()); got != tt.wantState {
+				t.Errorf("state = %s, want %s", got, tt.wantState)
+			}
+			// The probe still holds the only slot.
+			if _, err := b.allow(key, clock.Now()); !errors.Is(err, ErrCircuitOpen) {
+				t.Errorf("second probe admitted: %v", err)
+			}
+
+			b.record(key, probe, false, clock.Now())
+			if got := b.state(key, clock.Now()); got != BreakerClosed {
+				t.Errorf("state after probe = %s, want closed", got)
+			}
+			b.mu.Lock()
+			cb, ok := b.circuits[key]
+			b.mu.Unlock()
+			if ok && (cb.probes != 0 || cb.inflight != 0) {
+				t.Errorf("probes = %d, inflight = %d after all calls finished", cb.probes, cb.inflight)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerEviction(t *testing.T) {
+	tests := []struct {
+		name string
+		// failures recorded for every key before going idle.
+		failures  int
+		idle      time.Duration
+		wantKeeps int
+	}{
+		{"clean circuits are removed at once", 0, 0, 0},
+		{"failing circuits are kept", 1, time.Minute, 10},
+		{"idle failing circuits are removed", 1, breakerIdleTTL, 0},
+		{"open circuits are kept", 3, breakerIdleTTL, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBreaker(CircuitBreakerSettings{FailureThreshold: 3})
+			clock := newFakeClock()
+
+			for i := 0; i < 10; i++ {
+				key := BreakerKey{Endpoint: "/api/1/organizations", OrganizationID: fmt.Sprint(i)}
+				for j := 0; j < max(tt.failures, 1); j++ {
+					adm, err := b.allow(key, clock.Now())
+					if err != nil {
+						t.Fatal(err)
+					}
+					b.record(key, adm, tt.failures > 0, clock.Now())
+				}
+			}
+
+			// The next call of another key sweeps idle circuits.
+			clock.Advance(tt.idle)
+			other := BreakerKey{Endpoint: "/api/1/terminal_groups"}
+			adm, _ := b.allow(other, clock.Now())
+			b.record(other, adm, false, clock.Now())
+
+			b.mu.Lock()
+			got := len(b.circuits)
+			b.mu.Unlock()
+			if got != tt.wantKeeps {
+				t.Errorf("circuits = %d, want %d", got, tt.wantKeeps)
+			}
+		})
+	}
+}
+
+func TestIsBreakerFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"success", nil, false},
+		{"bad request", &ErrorResponse{StatusCode: http.StatusBadRequest}, false},
+		{"server error", &ErrorResponse{StatusCode: http.StatusBadGateway}, true},
+		{"gateway timeout", &ErrorResponse{StatusCode: http.StatusGatewayTimeout}, true},
+		{"transport", &TransportError{Err: errors.New("refused")}, true},
+		{"rate limit wait", &TransportError{Err: ErrRateLimitWait}, false},
+	}
+	for _, tt := range tests {
+		if got := isBreakerFailure(tt.err); got != tt.want {
+			t.Errorf("%s: isBreakerFailure = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+	})
+	clock := newFakeClock()
+	c := newTestClient(t, srv, WithClock(clock), WithCircuitBreaker(CircuitBreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute}))
+	ctx := context.Background()
+	key := BreakerKey{Endpoint: "/api/1/organizations"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Organizations(ctx, &OrganizationsRequest{}); !errors.Is(err, ErrServerError) {
+			t.Fatalf("call %d: err = %v", i, err)
+		}
+	}
+	if got := c.CircuitState(key); got != BreakerOpen {
+		t.Fatalf("state = %s, want open", got)
+	}
+
+	_, err := c.Organizations(ctx, &OrganizationsRequest{})
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || !openErr.RetryAt.Equal(clock.Now().Add(time.Minute)) {
+		t.Fatalf("err = %v, want CircuitOpenError", err)
+	}
+	if got := srv.callCount("/api/1/organizations"); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+
+	failing.Store(false)
+	clock.Advance(time.Minute)
+	if _, err = c.Organizations(ctx, &OrganizationsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.CircuitState(key); got != BreakerClosed {
+		t.Errorf("state = %s, want closed", got)
+	}
+}
+
+func TestCircuitBreakerConcurrent(t *testing.T) {
+	b := newTestBreaker(CircuitBreakerSettings{FailureThreshold: 1000})
+	clock := newFakeClock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				key := BreakerKey{Endpoint: "/api/1/organizations", OrganizationID: fmt.Sprint(j % 5)}
+				if adm, err := b.allow(key, clock.Now()); err == nil {
+					b.record(key, adm, j%3 == 0, clock.Now())
+				}
+				_ = b.state(key, clock.Now())
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -1,6 +1,10 @@
 package iiko
 
-import "github.com/google/uuid"
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
 
 type CitiesRequest struct {
 	OrganizationIDs []uuid.UUID `json:"organizationIds"`
@@ -25,15 +29,11 @@ type City struct {
 	AdditionalInfo   string    `json:"additionalInfo"`
 }
 
+var citiesEndpoint = NewEndpoint[CitiesRequest, CitiesResponse](EndpointInfo{Path: "/api/1/cities", RequiresAuth: true, Idempotent: true})
+
 // Cities ...
 //
 // iiko API: /api/1/cities
-func (c *Client) Cities(req *CitiesRequest, opts ...Option) (*CitiesResponse, error) {
-	var response CitiesResponse
-
-	if err := c.post(true, "/api/1/cities", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) Cities(ctx context.Context, req *CitiesRequest, opts ...Option) (*CitiesResponse, error) {
+	return citiesEndpoint.Call(ctx, c, req, opts...)
 }
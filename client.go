@@ -1,17 +1,30 @@
 package iiko
 
 import (
-	"errors"
+	"context"
+	"log/slog"
 	"net/http"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Client struct {
 	// Channel quit is used to notify that we should stop our JWT-refresh token Ticker.
 	quit chan struct{}
 
-	baseURL  string
+	// lifecycleMu guards closed and refresherStarted; refresher tracks the
+	// background refresh goroutine so that Close can wait for it.
+	lifecycleMu      sync.Mutex
+	closed           bool
+	refresherStarted bool
+	refresher        sync.WaitGroup
+
+	// lazy defers the first token request to the first API call.
+	lazy bool
+
+	baseURLs *baseURLPool
 	apiLogin string
 
 	// appId and clientSecret belong to the new iiko authorization scheme
@@ -19,10 +32,49 @@ type Client struct {
 	appId        string
 	clientSecret string
 
-	// tokenMu guards token against the data race between refreshTokenByInterval
-	// (writer) and request builders (readers).
-	tokenMu sync.RWMutex
-	token   string
+	// tokenMu guards token and refreshing against the data race between
+	// token refreshes (writers) and request builders (readers).
+	tokenMu    sync.RWMutex
+	token      accessTokenState
+	refreshing *tokenRefresh
+
+	clock               Clock
+	onTokenRefreshError func(err error)
+
+	// tokenStore is nil unless set by WithTokenStore. tokenOwner identifies
+	// this Client when it holds the store's refresh lock.
+	tokenStore TokenStore
+	tokenOwner string
+
+	// retryPolicy is nil unless set by WithRetryPolicy; nil disables retries.
+	retryPolicy *RetryPolicy
+
+	// limiters are nil unless set by WithRateLimits; nil disables throttling.
+	limiters map[EndpointClass]*tokenBucket
+
+	// middleware wraps every API call, outermost first.
+	middleware []Middleware
+
+	// logger is nil unless set by WithLogger.
+	logger *slog.Logger
+
+	// tracer is nil unless set by WithTracerProvider.
+	tracer trace.Tracer
+
+	// metrics is nil unless set by WithMetrics.
+	metrics MetricsCollector
+
+	// breaker is nil unless set by WithCircuitBreaker.
+	breaker *circuitBreaker
+
+	// coalescer is nil unless set by WithCoalescing.
+	coalescer *coalescer
+
+	// exchangeRecorder is nil unless set by WithExchangeRecorder.
+	exchangeRecorder func(ctx context.Context, ex *Exchange)
+
+	// drift is nil unless set by WithDriftDetection.
+	drift *driftDetector
 
 	httpClient           *http.Client
 	timeout              time.Duration
@@ -46,7 +98,8 @@ func (c *Client) SetTimeout(t time.Duration) {
 	c.timeout = t
 }
 
-// SetRefreshTokenInterval sets default Timeout header for all requests. By default 15 seconds.
+// SetRefreshTokenInterval sets how long after being issued the access token is
+// refreshed in background. By default 45 minutes.
 func (c *Client) SetRefreshTokenInterval(t time.Duration) {
 	c.refreshTokenInterval = t
 }
@@ -56,81 +109,93 @@ func (c *Client) SetHTTPClient(client *http.Client) {
 	c.httpClient = client
 }
 
-// getToken returns the current access token in a thread-safe way.
-func (c *Client) getToken() string {
-	c.tokenMu.RLock()
-	defer c.tokenMu.RUnlock()
-	return c.token
+// WithHTTPClient sets a custom http.Client for making API request to iikoCloud,
+// including the token request made by NewClient.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = client
+	}
 }
 
-// setToken stores a new access token in a thread-safe way.
-func (c *Client) setToken(token string) {
-	c.tokenMu.Lock()
-	defer c.tokenMu.Unlock()
-	c.token = token
+// Close stops the background token refresh and waits for it to exit,
+// aborting a refresh in flight. It is safe to call Close more than once.
+func (c *Client) Close() {
+	c.lifecycleMu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.quit)
+	}
+	c.lifecycleMu.Unlock()
+
+	c.refresher.Wait()
 }
 
-// refreshToken fetches a fresh access token from iikoCloud and stores it.
-// On any error it keeps the previous token untouched so in-flight requests
-// keep working until the next successful refresh.
-func (c *Client) refreshToken() error {
-	resp, err := c.accessToken(&AccessTokenRequest{
-		ApiLogin:     c.apiLogin,
-		AppId:        c.appId,
-		ClientSecret: c.clientSecret,
-	})
-	if err != nil {
-		return err
-	}
-	if resp == nil || resp.Token == "" {
-		return errors.New("iiko: empty access token in response")
+// WithLazyInit makes NewClient return without contacting iikoCloud. The first
+// access token is fetched by the first API call, and the background refresh
+// starts only after that. See Ready.
+func WithLazyInit() ClientOption {
+	return func(c *Client) {
+		c.lazy = true
 	}
-	c.setToken(resp.Token)
-	return nil
 }
 
-func (c *Client) refreshTokenByInterval() {
-	ticker := time.NewTicker(c.refreshTokenInterval)
-
-	for {
-		select {
-		case <-ticker.C:
-			// Ignore the error on purpose: keep the old (still valid) token and
-			// retry on the next tick. Never panic on a nil response — that used
-			// to kill this goroutine and freeze the token forever, causing 401s
-			// an hour later.
-			_ = c.refreshToken()
-
-		case <-c.quit:
-			ticker.Stop()
-			return
-		}
-	}
+// Ready reports whether the Client holds an unexpired access token, i.e.
+// whether iikoCloud has been reachable. It suits readiness probes.
+func (c *Client) Ready() bool {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.token.value != "" && c.clock.Now().Before(c.token.expiresAt)
 }
 
-func (c *Client) Close() {
-	close(c.quit)
+// startRefresher starts the background token refresh once, unless the
+// Client is already closed.
+func (c *Client) startRefresher() {
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+
+	if c.closed || c.refresherStarted {
+		return
+	}
+	c.refresherStarted = true
+
+	c.refresher.Add(1)
+	go func() {
+		defer c.refresher.Done()
+		c.refreshTokenByInterval()
+	}()
 }
 
+// NewClient creates a Client and fetches the first access token.
 func NewClient(apiLogin string, opts ...ClientOption) (*Client, error) {
+	return NewClientWithContext(context.Background(), apiLogin, opts...)
+}
+
+// NewClientWithContext is like NewClient but uses ctx for the initial
+// access token request. With WithLazyInit ctx is unused.
+func NewClientWithContext(ctx context.Context, apiLogin string, opts ...ClientOption) (*Client, error) {
 	client := &Client{
-		baseURL:              BaseURL,
+		baseURLs:             newBaseURLPool(DefaultFailoverCooldown, BaseURL),
 		httpClient:           http.DefaultClient,
 		apiLogin:             apiLogin,
 		timeout:              DefaultTimeout,
 		refreshTokenInterval: DefaultRefreshTokenInterval,
 		quit:                 make(chan struct{}),
+		clock:                realClock{},
+		tokenOwner:           newTokenOwner(),
 	}
 
 	for _, opt := range opts {
 		opt(client)
 	}
 
-	if err := client.refreshToken(); err != nil {
-		return nil, err
+	if client.lazy {
+		return client, nil
 	}
 
-	go client.refreshTokenByInterval()
+	if err := client.refreshToken(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
 
 	return client, nil
 }
@@ -0,0 +1,258 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewClientInit(t *testing.T) {
+	tests := []struct {
+		name         string
+		lazy         bool
+		wantRequests int32
+		wantReady    bool
+	}{
+		{"eager", false, 1, true},
+		{"lazy", true, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTokenServer(t, nil)
+			opts := []ClientOption{WithBaseURL(srv.URL)}
+			if tt.lazy {
+				opts = append(opts, WithLazyInit())
+			}
+
+			c, err := NewClient("test", opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			if got := srv.requests.Load(); got != tt.wantRequests {
+				t.Errorf("token requests = %d, want %d", got, tt.wantRequests)
+			}
+			if c.Ready() != tt.wantReady {
+				t.Errorf("Ready() = %v, want %v", c.Ready(), tt.wantReady)
+			}
+
+			// The first call of a lazy Client fetches the token.
+			if _, err = c.Organizations(context.Background(), &OrganizationsRequest{}); err != nil {
+				t.Fatal(err)
+			}
+			if got := srv.requests.Load(); got != 1 {
+				t.Errorf("token requests after a call = %d, want 1", got)
+			}
+			if !c.Ready() {
+				t.Error("Ready() = false after a call")
+			}
+		})
+	}
+}
+
+func TestNewClientTokenError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusUnauthorized, `{"errorDescription":"Login is not authorized"}`)
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient("test", WithBaseURL(srv.URL)); err == nil {
+		t.Fatal("NewClient succeeded without a token")
+	}
+
+	// A lazy Client reports the error on the first call instead.
+	c, err := NewClient("test", WithBaseURL(srv.URL), WithLazyInit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var apiErr *ErrorResponse
+	if _, err = c.Organizations(context.Background(), &OrganizationsRequest{}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("err = %v, want a 401 ErrorResponse", err)
+	}
+	if c.Ready() {
+		t.Error("Ready() = true without a token")
+	}
+}
+
+func TestNewClientWithContextCancelled(t *testing.T) {
+	srv := newTokenServer(t, make(chan struct{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := NewClientWithContext(ctx, "test", WithBaseURL(srv.URL)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestReadyExpiry(t *testing.T) {
+	srv := newTokenServer(t, nil)
+	clock := newFakeClock()
+	c := newLazyClient(t, srv.URL, WithClock(clock))
+	if _, err := c.validToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.Ready() {
+		t.Fatal("Ready() = false with a fresh token")
+	}
+	clock.Advance(DefaultTokenLifetime)
+	if c.Ready() {
+		t.Error("Ready() = true with an expired token")
+	}
+}
+
+func TestClose(t *testing.T) {
+	srv := newTokenServer(t, nil)
+	c, err := NewClient("test", WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Close()
+		}()
+	}
+	wg.Wait()
+	c.Close()
+
+	// The refresher is not restarted by a refresh after Close.
+	c.startRefresher()
+	c.lifecycleMu.Lock()
+	started := c.refresherStarted
+	c.lifecycleMu.Unlock()
+	if !started {
+		t.Error("refresher of an eager Client was never started")
+	}
+
+	lazy, err := NewClient("test", WithBaseURL(srv.URL), WithLazyInit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lazy.Close()
+	lazy.startRefresher()
+	lazy.lifecycleMu.Lock()
+	started = lazy.refresherStarted
+	lazy.lifecycleMu.Unlock()
+	if started {
+		t.Error("refresher started after Close")
+	}
+}
+
+// closeCountingTransport counts closed response bodies, so that a test knows
+// the client is done with a response.
+type closeCountingTransport struct {
+	closed atomic.Int32
+}
+
+func (t *closeCountingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(r)
+	if err == nil {
+		resp.Body = &countingBody{ReadCloser: resp.Body, closed: &t.closed}
+	}
+	return resp, err
+}
+
+type countingBody struct {
+	io.ReadCloser
+	closed *atomic.Int32
+}
+
+func (b *countingBody) Close() error {
+	b.closed.Add(1)
+	return b.ReadCloser.Close()
+}
+
+func TestCancelAbortsCall(t *testing.T) {
+	const endpoint = "/api/1/organizations"
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		opts    []ClientOption
+		// warmup calls go through before the cancelled one.
+		warmup int
+		// blocked reports whether the cancelled call is stuck where the
+		// test means to cancel it.
+		blocked func(c *Client, srv *testServer, tr *closeCountingTransport) bool
+	}{
+		{
+			name: "blocked handler",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// The server notices a client going away only once the body is read.
+				_, _ = io.Copy(io.Discard, r.Body)
+				<-r.Context().Done()
+			},
+			blocked: func(_ *Client, srv *testServer, _ *closeCountingTransport) bool {
+				return srv.callCount(endpoint) == 1
+			},
+		},
+		{
+			name: "retry backoff",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusServiceUnavailable, `{}`)
+			},
+			opts: []ClientOption{WithRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})},
+			// The failed response is discarded right before the backoff.
+			blocked: func(_ *Client, srv *testServer, tr *closeCountingTransport) bool {
+				return srv.callCount(endpoint) == 1 && tr.closed.Load() == 2
+			},
+		},
+		{
+			name:    "rate limiter wait",
+			handler: ok,
+			opts:    []ClientOption{WithRateLimits(RateLimits{Reads: RateLimit{Rate: 0.001, Burst: 1}})},
+			warmup:  1,
+			blocked: func(c *Client, _ *testServer, _ *closeCountingTransport) bool {
+				b := c.limiters[ReadEndpoint]
+				b.mu.Lock()
+				defer b.mu.Unlock()
+				return b.tokens < 0
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, tt.handler)
+			tr := &closeCountingTransport{}
+			c := newTestClient(t, srv, append([]ClientOption{WithHTTPClient(&http.Client{Transport: tr})}, tt.opts...)...)
+			for i := 0; i < tt.warmup; i++ {
+				if _, err := c.Organizations(context.Background(), &OrganizationsRequest{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			errc := make(chan error, 1)
+			go func() {
+				_, err := c.Organizations(ctx, &OrganizationsRequest{})
+				errc <- err
+			}()
+
+			waitFor(t, "the call to block", func() bool { return tt.blocked(c, srv, tr) })
+			calls := srv.callCount(endpoint)
+			cancel()
+			if err := <-errc; !errors.Is(err, context.Canceled) {
+				t.Fatalf("err = %v, want context.Canceled", err)
+			}
+			if got := srv.callCount(endpoint); got != calls {
+				t.Errorf("%d attempts after cancel", got-calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package iiko
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// WithCoalescing makes concurrent identical calls to read-only endpoints share
+// one HTTP round trip and one decoded result. Calls are identical when they go
+// to the same endpoint with the same JSON body, regardless of key order.
+//
+// If no endpoints are given, every idempotent endpoint is coalesced. Mutations
+// are never coalesced, and neither are calls with per-call options.
+//
+// Coalesced callers receive the same response value, so it must be treated as
+// read-only. The shared call goes through the middleware chain once, and is
+// cancelled only when every caller waiting for it has given up.
+func WithCoalescing(endpoints ...string) ClientOption {
+	return func(c *Client) {
+		g := &coalescer{flights: make(map[string]*flight)}
+		if len(endpoints) > 0 {
+			g.endpoints = make(map[string]bool, len(endpoints))
+			for _, endpoint := range endpoints {
+				g.endpoints[endpoint] = true
+			}
+		}
+		c.coalescer = g
+	}
+}
+
+type coalescer struct {
+	// endpoints to coalesce; nil means every idempotent one.
+	endpoints map[string]bool
+
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is one shared call in progress.
+type flight struct {
+	done    chan struct{}
+	val     interface{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// coalesces reports whether calls to the endpoint described by info with opts
+// may be shared.
+func (g *coalescer) coalesces(info EndpointInfo, opts []Option) bool {
+	if len(opts) > 0 || !info.Idempotent || !info.RequiresAuth {
+		return false
+	}
+	return g.endpoints == nil || g.endpoints[info.Path]
+}
+
+// do runs fn once for all concurrent callers with the same key. fn gets a
+// context that keeps the values of the first caller's ctx and is cancelled
+// once no caller waits for the result any more.
+func (g *coalescer) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	f, ok := g.flights[key]
+	if !ok {
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.flights[key] = f
+
+		go func() {
+			f.val, f.err = fn(flightCtx)
+			cancel()
+
+			g.mu.Lock()
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+			g.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		if f.waiters--; f.waiters == 0 {
+			f.cancel()
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// coalesceKey identifies calls to endpoint with body. Bodies are compacted to
+// a canonical form so that key order and whitespace don't matter.
+func coalesceKey(endpoint string, body []byte) (string, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return endpoint + "\n" + string(canonical), nil
+}
@@ -0,0 +1,220 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesceKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{
+		{"key order", `{"a":1,"b":[1,2]}`, `{"b":[1,2],"a":1}`, true},
+		{"whitespace", `{"a": 1}`, "{\n\"a\":1}", true},
+		{"numbers kept exact", `{"a":1.0}`, `{"a":1}`, false},
+		{"different values", `{"a":1}`, `{"a":2}`, false},
+		{"array order", `[1,2]`, `[2,1]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := coalesceKey("/api/1/organizations", []byte(tt.a))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := coalesceKey("/api/1/organizations", []byte(tt.b))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (a == b) != tt.equal {
+				t.Errorf("keys %q and %q, want equal %v", a, b, tt.equal)
+			}
+		})
+	}
+
+	if a, _ := coalesceKey("/a", []byte(`{}`)); a == mustCoalesceKey(t, "/b", `{}`) {
+		t.Error("endpoints share a key")
+	}
+	if _, err := coalesceKey("/a", []byte(`{`)); err == nil {
+		t.Error("invalid body accepted")
+	}
+}
+
+func mustCoalesceKey(t *testing.T, endpoint, body string) string {
+	t.Helper()
+	key, err := coalesceKey(endpoint, []byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestCoalescerCoalesces(t *testing.T) {
+	read := EndpointInfo{Path: "/api/1/organizations", RequiresAuth: true, Idempotent: true}
+	tests := []struct {
+		name      string
+		endpoints []string
+		info      EndpointInfo
+		opts      []Option
+		want      bool
+	}{
+		{"idempotent read", nil, read, nil, true},
+		{"mutation", nil, EndpointInfo{Path: "/api/1/deliveries/create", RequiresAuth: true}, nil, false},
+		{"token request", nil, EndpointInfo{Path: "/api/1/access_token", Idempotent: true}, nil, false},
+		{"per-call options", nil, read, []Option{WithCustomTimeout(time.Second)}, false},
+		{"listed endpoint", []string{"/api/1/organizations"}, read, nil, true},
+		{"unlisted endpoint", []string{"/api/1/terminal_groups"}, read, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{}
+			WithCoalescing(tt.endpoints...)(c)
+			if got := c.coalescer.coalesces(tt.info, tt.opts); got != tt.want {
+				t.Errorf("coalesces = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoalescerDo(t *testing.T) {
+	errCall := errors.New("call failed")
+
+	tests := []struct {
+		name    string
+		callers int
+		// cancelled is how many callers give up before the result.
+		cancelled     int
+		err           error
+		wantFlightErr bool
+	}{
+		{"shared result", 10, 0, nil, false},
+		{"shared error", 10, 0, errCall, false},
+		{"some callers leave", 10, 5, nil, false},
+		{"every caller leaves", 3, 3, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &coalescer{flights: make(map[string]*flight)}
+			release := make(chan struct{})
+			var calls atomic.Int32
+			flightErr := make(chan error, 1)
+
+			fn := func(ctx context.Context) (interface{}, error) {
+				calls.Add(1)
+				select {
+				case <-release:
+					return "result", tt.err
+				case <-ctx.Done():
+					flightErr <- ctx.Err()
+					return nil, ctx.Err()
+				}
+			}
+
+			var wg sync.WaitGroup
+			results := make([]interface{}, tt.callers)
+			errs := make([]error, tt.callers)
+			cancels := make([]context.CancelFunc, tt.callers)
+			for i := 0; i < tt.callers; i++ {
+				var ctx context.Context
+				ctx, cancels[i] = context.WithCancel(context.Background())
+				defer cancels[i]()
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], errs[i] = g.do(ctx, "key", fn)
+				}(i)
+			}
+
+			waitFor(t, "callers", func() bool { return flightWaiters(g) == tt.callers })
+			for _, cancel := range cancels[:tt.cancelled] {
+				cancel()
+			}
+			waitFor(t, "callers to leave", func() bool { return flightWaiters(g) == tt.callers-tt.cancelled })
+			if tt.wantFlightErr {
+				if err := <-flightErr; !errors.Is(err, context.Canceled) {
+					t.Errorf("shared call err = %v, want context.Canceled", err)
+				}
+			}
+			close(release)
+			wg.Wait()
+
+			if got := calls.Load(); got != 1 {
+				t.Errorf("calls = %d, want 1", got)
+			}
+			for i := range errs {
+				switch {
+				case i < tt.cancelled:
+					if !errors.Is(errs[i], context.Canceled) {
+						t.Errorf("caller %d: err = %v, want context.Canceled", i, errs[i])
+					}
+				case !errors.Is(errs[i], tt.err) || results[i] != "result":
+					t.Errorf("caller %d: %v, %v", i, results[i], errs[i])
+				}
+			}
+			select {
+			case err := <-flightErr:
+				t.Errorf("shared call cancelled: %v", err)
+			default:
+			}
+
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			if len(g.flights) != 0 {
+				t.Errorf("%d flights left", len(g.flights))
+			}
+		})
+	}
+}
+
+func TestClientCoalescing(t *testing.T) {
+	release := make(chan struct{})
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`","organizations":[{"name":"A"}]}`)
+	})
+	c := newTestClient(t, srv, WithCoalescing())
+
+	var wg sync.WaitGroup
+	responses := make([]*OrganizationsResponse, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := c.Organizations(context.Background(), &OrganizationsRequest{})
+			if err != nil {
+				t.Error(err)
+			}
+			responses[i] = res
+		}(i)
+	}
+	waitFor(t, "callers", func() bool { return flightWaiters(c.coalescer) == len(responses) })
+	close(release)
+	wg.Wait()
+
+	if got := srv.callCount("/api/1/organizations"); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+	for i, res := range responses {
+		if res != responses[0] || len(res.Organizations) != 1 {
+			t.Errorf("caller %d got %p, want the shared %p", i, res, responses[0])
+		}
+	}
+}
+
+// flightWaiters returns how many callers wait for the flights of g.
+func flightWaiters(g *coalescer) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	n := 0
+	for _, f := range g.flights {
+		n += f.waiters
+	}
+	return n
+}
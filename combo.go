@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -17,7 +19,7 @@ type Products struct {
 	ProductID               uuid.UUID `json:"productId"`
 	SizeID                  uuid.UUID `json:"sizeId"`
 	ForbiddenModifiers      []string  `json:"forbiddenModifiers"`
-	PriceModificationAmount int       `json:"priceModificationAmount"`
+	PriceModificationAmount Money     `json:"priceModificationAmount"`
 }
 
 type Groups struct {
@@ -31,7 +33,7 @@ type ComboSpecification struct {
 	CategoryID            uuid.UUID `json:"categoryId"`
 	Name                  string    `json:"name"`
 	PriceModificationType int       `json:"priceModificationType"`
-	PriceModification     int       `json:"priceModification"`
+	PriceModification     Money     `json:"priceModification"`
 	Groups                []Groups  `json:"groups"`
 }
 
@@ -40,15 +42,11 @@ type ComboCategory struct {
 	Name string    `json:"name"`
 }
 
+var comboGetCombosInfoEndpoint = NewEndpoint[ComboGetCombosInfoRequest, ComboGetCombosInfoResponse](EndpointInfo{Path: "/api/1/combo/get_combos_info", RequiresAuth: true, Idempotent: true})
+
 // Get combos info
 //
 // iiko API: /api/1/combo/get_combos_info
-func (c *Client) ComboGetCombosInfo(req *ComboGetCombosInfoRequest, opts ...Option) (*ComboGetCombosInfoResponse, error) {
-	var response ComboGetCombosInfoResponse
-
-	if err := c.post(true, "/api/1/combo/get_combos_info", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) ComboGetCombosInfo(ctx context.Context, req *ComboGetCombosInfoRequest, opts ...Option) (*ComboGetCombosInfoResponse, error) {
+	return comboGetCombosInfoEndpoint.Call(ctx, c, req, opts...)
 }
@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -25,15 +27,11 @@ type CommandsStatusResponse struct {
 	OrderInfo        OrderInfo          `json:"orderInfo"`
 }
 
+var commandsStatusEndpoint = NewEndpoint[CommandsStatusRequest, CommandsStatusResponse](EndpointInfo{Path: "/api/1/commands/status", RequiresAuth: true, Idempotent: true})
+
 // Get status of command.
 //
 // iiko API: /api/1/commands/status
-func (c *Client) CommandsStatus(req *CommandsStatusRequest, opts ...Option) (*CommandsStatusResponse, error) {
-	var response CommandsStatusResponse
-
-	if err := c.post(true, "/api/1/commands/status", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) CommandsStatus(ctx context.Context, req *CommandsStatusRequest, opts ...Option) (*CommandsStatusResponse, error) {
+	return commandsStatusEndpoint.Call(ctx, c, req, opts...)
 }
@@ -1,5 +1,7 @@
 package iiko
 
+import "context"
+
 type CreateOrUpdateRequest struct {
 	// Customer uuid
 	Id *string `json:"id"`
@@ -16,7 +18,7 @@ type CreateOrUpdateRequest struct {
 	// Customer surname
 	SurName *string `json:"surName"`
 	// Customer birthday <yyyy-MM-dd HH:mm:ss.fff>
-	Birthday *string `json:"birthday"`
+	Birthday *Time `json:"birthday"`
 	// Customer email
 	Email         *string       `json:"email"`
 	Sex           SexType       `json:"sex"`
@@ -36,15 +38,11 @@ type CreateOrUpdateResponse struct {
 	Id string `json:"id"`
 }
 
+var createOrUpdateEndpoint = NewEndpoint[CreateOrUpdateRequest, CreateOrUpdateResponse](EndpointInfo{Path: "/api/1/loyalty/iiko/customer/create_or_update", RequiresAuth: true})
+
 // CreateOrUpdate Create or update customer info by id or phone or card track
 //
 // iiko API: /api/1/loyalty/iiko/customer/create_or_update
-func (c *Client) CreateOrUpdate(req *CreateOrUpdateRequest, opts ...Option) (*CreateOrUpdateResponse, error) {
-	var response CreateOrUpdateResponse
-
-	if err := c.post(true, "/api/1/loyalty/iiko/customer/create_or_update", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) CreateOrUpdate(ctx context.Context, req *CreateOrUpdateRequest, opts ...Option) (*CreateOrUpdateResponse, error) {
+	return createOrUpdateEndpoint.Call(ctx, c, req, opts...)
 }
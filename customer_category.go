@@ -1,5 +1,7 @@
 package iiko
 
+import "context"
+
 // CustomerCategoriesRequest represents request body for getting customer categories
 type CustomerCategoriesRequest struct {
 	OrganizationId string `json:"organizationId"`
@@ -24,43 +26,31 @@ type CustomerCategoryRemoveRequest struct {
 	OrganizationId string `json:"organizationId"`
 }
 
+var customerCategoriesEndpoint = NewEndpoint[CustomerCategoriesRequest, CustomerCategoriesResponse](EndpointInfo{Path: "/api/1/loyalty/iiko/customer_category", RequiresAuth: true, Idempotent: true})
+
 // CustomerCategories gets customer categories for organization
 //
 // iiko API: POST /api/1/loyalty/iiko/customer_category
-func (c *Client) CustomerCategories(req *CustomerCategoriesRequest, opts ...Option) (*CustomerCategoriesResponse, error) {
-	var response CustomerCategoriesResponse
-
-	if err := c.post(true, "/api/1/loyalty/iiko/customer_category", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) CustomerCategories(ctx context.Context, req *CustomerCategoriesRequest, opts ...Option) (*CustomerCategoriesResponse, error) {
+	return customerCategoriesEndpoint.Call(ctx, c, req, opts...)
 }
 
+var customerCategoryAddEndpoint = NewEndpoint[CustomerCategoryAddRequest, struct{}](EndpointInfo{Path: "/api/1/loyalty/iiko/customer_category/add", RequiresAuth: true})
+
 // CustomerCategoryAdd adds category to customer
 //
 // iiko API: POST /api/1/loyalty/iiko/customer_category/add
-func (c *Client) CustomerCategoryAdd(req *CustomerCategoryAddRequest, opts ...Option) error {
-	// Create empty response since API returns no response body
-	var response interface{}
-
-	if err := c.post(true, "/api/1/loyalty/iiko/customer_category/add", req, &response, opts...); err != nil {
-		return err
-	}
-
-	return nil
+func (c *Client) CustomerCategoryAdd(ctx context.Context, req *CustomerCategoryAddRequest, opts ...Option) error {
+	_, err := customerCategoryAddEndpoint.Call(ctx, c, req, opts...)
+	return err
 }
 
+var customerCategoryRemoveEndpoint = NewEndpoint[CustomerCategoryRemoveRequest, struct{}](EndpointInfo{Path: "/api/1/loyalty/iiko/customer_category/remove", RequiresAuth: true})
+
 // CustomerCategoryRemove removes category from customer
 //
 // iiko API: POST /api/1/loyalty/iiko/customer_category/remove
-func (c *Client) CustomerCategoryRemove(req *CustomerCategoryRemoveRequest, opts ...Option) error {
-	// Create empty response since API returns no response body
-	var response interface{}
-
-	if err := c.post(true, "/api/1/loyalty/iiko/customer_category/remove", req, &response, opts...); err != nil {
-		return err
-	}
-
-	return nil
+func (c *Client) CustomerCategoryRemove(ctx context.Context, req *CustomerCategoryRemoveRequest, opts ...Option) error {
+	_, err := customerCategoryRemoveEndpoint.Call(ctx, c, req, opts...)
+	return err
 }
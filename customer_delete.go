@@ -1,6 +1,10 @@
 package iiko
 
-import "github.com/google/uuid"
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
 
 // DeleteCustomersRequest represents the request structure for deleting customers
 type DeleteCustomersRequest struct {
@@ -20,15 +24,11 @@ type DeleteCustomersResponse struct {
 	NotFound int `json:"notFound"`
 }
 
+var deleteCustomersEndpoint = NewEndpoint[DeleteCustomersRequest, DeleteCustomersResponse](EndpointInfo{Path: "/api/1/loyalty/iiko/delete_customers", RequiresAuth: true})
+
 // DeleteCustomers Delete customers by their IDs
 //
 // iiko API: /api/1/loyalty/iiko/delete_customers
-func (c *Client) DeleteCustomers(req *DeleteCustomersRequest, opts ...Option) (*DeleteCustomersResponse, error) {
-	var response DeleteCustomersResponse
-
-	if err := c.post(true, "/api/1/loyalty/iiko/delete_customers", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) DeleteCustomers(ctx context.Context, req *DeleteCustomersRequest, opts ...Option) (*DeleteCustomersResponse, error) {
+	return deleteCustomersEndpoint.Call(ctx, c, req, opts...)
 }
@@ -1,5 +1,7 @@
 package iiko
 
+import "context"
+
 type CustomerInfoType string
 
 const (
@@ -45,7 +47,7 @@ type CustomerInfoResponse struct {
 	Comment                       *string         `json:"comment"`
 	Phone                         *string         `json:"phone"`
 	CultureName                   *string         `json:"cultureName"`
-	Birthday                      *string         `json:"birthday"`
+	Birthday                      *Time           `json:"birthday"`
 	Email                         *string         `json:"email"`
 	Sex                           SexType         `json:"sex"`
 	ConsentStatus                 ConsentStatus   `json:"consentStatus"`
@@ -64,15 +66,11 @@ type CustomerInfoResponse struct {
 	IsDeleted                     *bool           `json:"isDeleted"`
 }
 
+var customerInfoEndpoint = NewEndpoint[CustomerInfoRequest, CustomerInfoResponse](EndpointInfo{Path: "/api/1/loyalty/iiko/customer/info", RequiresAuth: true, Idempotent: true})
+
 // CustomerInfo ...
 //
 // iiko API: /api/1/loyalty/iiko/customer/info
-func (c *Client) CustomerInfo(req *CustomerInfoRequest, opts ...Option) (*CustomerInfoResponse, error) {
-	var response CustomerInfoResponse
-
-	if err := c.post(true, "/api/1/loyalty/iiko/customer/info", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) CustomerInfo(ctx context.Context, req *CustomerInfoRequest, opts ...Option) (*CustomerInfoResponse, error) {
+	return customerInfoEndpoint.Call(ctx, c, req, opts...)
 }
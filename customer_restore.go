@@ -1,6 +1,10 @@
 package iiko
 
-import "github.com/google/uuid"
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
 
 // RestoreCustomersRequest represents the request structure for restoring customers
 type RestoreCustomersRequest struct {
@@ -20,15 +24,11 @@ type RestoreCustomersResponse struct {
 	NotFound int `json:"notFound"`
 }
 
+var restoreCustomersEndpoint = NewEndpoint[RestoreCustomersRequest, RestoreCustomersResponse](EndpointInfo{Path: "/api/1/loyalty/iiko/restore_customers", RequiresAuth: true})
+
 // RestoreCustomers Restore customers by their IDs
 //
 // iiko API: /api/1/loyalty/iiko/restore_customers
-func (c *Client) RestoreCustomers(req *RestoreCustomersRequest, opts ...Option) (*RestoreCustomersResponse, error) {
-	var response RestoreCustomersResponse
-
-	if err := c.post(true, "/api/1/loyalty/iiko/restore_customers", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) RestoreCustomers(ctx context.Context, req *RestoreCustomersRequest, opts ...Option) (*RestoreCustomersResponse, error) {
+	return restoreCustomersEndpoint.Call(ctx, c, req, opts...)
 }
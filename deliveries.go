@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -52,15 +54,11 @@ type DeliveriesOrderTypesResponse struct {
 	OrderTypes []OrderType `json:"orderTypes"`
 }
 
+var deliveriesOrderTypesEndpoint = NewEndpoint[DeliveriesOrderTypesRequest, DeliveriesOrderTypesResponse](EndpointInfo{Path: "/api/1/deliveries/order_types", RequiresAuth: true, Idempotent: true})
+
 // Order types.
 //
 // iiko API: /api/1/deliveries/order_types
-func (c *Client) DeliveriesOrderTypes(req *DeliveriesOrderTypesRequest, opts ...Option) (*DeliveriesOrderTypesResponse, error) {
-	var response DeliveriesOrderTypesResponse
-
-	if err := c.post(true, "/api/1/deliveries/order_types", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) DeliveriesOrderTypes(ctx context.Context, req *DeliveriesOrderTypesRequest, opts ...Option) (*DeliveriesOrderTypesResponse, error) {
+	return deliveriesOrderTypesEndpoint.Call(ctx, c, req, opts...)
 }
@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -72,19 +74,19 @@ type DeliveryOrder struct {
 	// Courier information.
 	CourierInfo *DeliveryCourierInfo `json:"courierInfo,omitempty"`
 	// Complete before time.
-	CompleteBefore *EventTime `json:"completeBefore,omitempty"`
+	CompleteBefore *Time `json:"completeBefore,omitempty"`
 	// Order creation date.
-	WhenCreated EventTime `json:"whenCreated"`
+	WhenCreated Time `json:"whenCreated"`
 	// When confirmed.
-	WhenConfirmed *EventTime `json:"whenConfirmed,omitempty"`
+	WhenConfirmed *Time `json:"whenConfirmed,omitempty"`
 	// When printed.
-	WhenPrinted *EventTime `json:"whenPrinted,omitempty"`
+	WhenPrinted *Time `json:"whenPrinted,omitempty"`
 	// When cooking completed.
-	WhenCookingCompleted *EventTime `json:"whenCookingCompleted,omitempty"`
+	WhenCookingCompleted *Time `json:"whenCookingCompleted,omitempty"`
 	// When sent.
-	WhenSended *EventTime `json:"whenSended,omitempty"`
+	WhenSended *Time `json:"whenSended,omitempty"`
 	// When delivered.
-	WhenDelivered *EventTime `json:"whenDelivered,omitempty"`
+	WhenDelivered *Time `json:"whenDelivered,omitempty"`
 	// Comment.
 	Comment string `json:"comment"`
 	// Problem information.
@@ -98,13 +100,13 @@ type DeliveryOrder struct {
 	// Index in courier route.
 	IndexInCourierRoute int `json:"indexInCourierRoute"`
 	// Cooking start time.
-	CookingStartTime *EventTime `json:"cookingStartTime,omitempty"`
+	CookingStartTime *Time `json:"cookingStartTime,omitempty"`
 	// Is deleted flag.
 	IsDeleted bool `json:"isDeleted"`
 	// When received by API.
-	WhenReceivedByApi *EventTime `json:"whenReceivedByApi,omitempty"`
+	WhenReceivedByApi *Time `json:"whenReceivedByApi,omitempty"`
 	// When received from front.
-	WhenReceivedFromFront *EventTime `json:"whenReceivedFromFront,omitempty"`
+	WhenReceivedFromFront *Time `json:"whenReceivedFromFront,omitempty"`
 	// Moved from delivery ID.
 	MovedFromDeliveryID *uuid.UUID `json:"movedFromDeliveryId,omitempty"`
 	// Moved from terminal group ID.
@@ -124,25 +126,25 @@ type DeliveryOrder struct {
 	// Delivery zone.
 	DeliveryZone string `json:"deliveryZone"`
 	// Locked at time.
-	LockedAt *EventTime `json:"lockedAt,omitempty"`
+	LockedAt *Time `json:"lockedAt,omitempty"`
 	// Estimated time.
-	EstimatedTime *EventTime `json:"estimatedTime,omitempty"`
+	EstimatedTime *Time `json:"estimatedTime,omitempty"`
 	// Is ASAP flag.
 	IsAsap bool `json:"isAsap"`
 	// When packed.
-	WhenPacked *EventTime `json:"whenPacked,omitempty"`
+	WhenPacked *Time `json:"whenPacked,omitempty"`
 	// Price category.
 	PriceCategory *DeliveryPriceCategory `json:"priceCategory,omitempty"`
 	// Order sum.
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 	// Order number.
 	Number int `json:"number"`
 	// Source key.
 	SourceKey string `json:"sourceKey"`
 	// Invoice printing time.
-	WhenBillPrinted *EventTime `json:"whenBillPrinted,omitempty"`
+	WhenBillPrinted *Time `json:"whenBillPrinted,omitempty"`
 	// Delivery closing time.
-	WhenClosed *EventTime `json:"whenClosed,omitempty"`
+	WhenClosed *Time `json:"whenClosed,omitempty"`
 	// Concept.
 	Conception Conception `json:"conception"`
 	// Guests information.
@@ -162,7 +164,7 @@ type DeliveryOrder struct {
 	// Terminal group ID.
 	TerminalGroupID uuid.UUID `json:"terminalGroupId"`
 	// Processed payments sum.
-	ProcessedPaymentsSum float64 `json:"processedPaymentsSum"`
+	ProcessedPaymentsSum Money `json:"processedPaymentsSum"`
 	// Loyalty information.
 	LoyaltyInfo *DeliveryLoyaltyInfo `json:"loyaltyInfo,omitempty"`
 	// External data.
@@ -195,7 +197,7 @@ type DeliveryCustomer struct {
 // DeliveryCancelInfo represents cancel information
 type DeliveryCancelInfo struct {
 	// When cancelled
-	WhenCancelled EventTime `json:"whenCancelled"`
+	WhenCancelled Time `json:"whenCancelled"`
 	// Cancel cause
 	Cause *DeliveryCancelCause `json:"cause,omitempty"`
 	// Comment
@@ -283,7 +285,7 @@ type DeliveryItem struct {
 	// Comment
 	Comment string `json:"comment"`
 	// When printed
-	WhenPrinted *EventTime `json:"whenPrinted,omitempty"`
+	WhenPrinted *Time `json:"whenPrinted,omitempty"`
 	// Size
 	Size *DeliverySize `json:"size,omitempty"`
 	// Combo information
@@ -353,7 +355,7 @@ type DeliveryCombo struct {
 	// Amount
 	Amount int `json:"amount"`
 	// Price
-	Price float64 `json:"price"`
+	Price Money `json:"price"`
 	// Source ID
 	SourceID uuid.UUID `json:"sourceId"`
 	// Size
@@ -365,7 +367,7 @@ type DeliveryPayment struct {
 	// Payment type
 	PaymentType *DeliveryPaymentType `json:"paymentType,omitempty"`
 	// Sum
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 	// Is preliminary
 	IsPreliminary bool `json:"isPreliminary"`
 	// Is external
@@ -395,7 +397,7 @@ type DeliveryTip struct {
 	// Payment type
 	PaymentType *DeliveryPaymentType `json:"paymentType,omitempty"`
 	// Sum
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 	// Is preliminary
 	IsPreliminary bool `json:"isPreliminary"`
 	// Is external
@@ -426,15 +428,11 @@ type DeliveryOrderType struct {
 	OrderServiceType OrderServiceType `json:"orderServiceType"`
 }
 
+var deliveriesByIDEndpoint = NewEndpoint[DeliveriesByIDRequest, DeliveriesByIDResponse](EndpointInfo{Path: "/api/1/deliveries/by_id", RequiresAuth: true, Idempotent: true})
+
 // DeliveriesByID Get delivery orders by IDs
 //
 // iiko API: /api/1/deliveries/by_id
-func (c *Client) DeliveriesByID(req *DeliveriesByIDRequest, opts ...Option) (*DeliveriesByIDResponse, error) {
-	var response DeliveriesByIDResponse
-
-	if err := c.post(true, "/api/1/deliveries/by_id", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) DeliveriesByID(ctx context.Context, req *DeliveriesByIDRequest, opts ...Option) (*DeliveriesByIDResponse, error) {
+	return deliveriesByIDEndpoint.Call(ctx, c, req, opts...)
 }
@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -25,7 +27,7 @@ type CreateDeliveryOrderSettings struct {
 	// External number
 	ExternalNumber *string `json:"externalNumber"`
 	// Complete before time
-	CompleteBefore *string `json:"completeBefore"`
+	CompleteBefore *Time `json:"completeBefore"`
 	// Phone number
 	Phone string `json:"phone"`
 	// Phone extension
@@ -136,7 +138,7 @@ type DeliveryCity struct {
 type DeliveryOrderItem struct {
 	ProductID string `json:"productId"`
 	Modifiers []*Modifier `json:"modifiers"`
-	Price float64 `json:"price"`
+	Price Money `json:"price"`
 	PositionID *string `json:"positionId"`
 	// Item type
 	Type string `json:"type"`
@@ -159,7 +161,7 @@ type DeliveryOrderCombo struct {
 	// Combo amount
 	Amount int `json:"amount"`
 	// Combo price
-	Price float64 `json:"price"`
+	Price Money `json:"price"`
 	// Source ID
 	SourceId uuid.UUID `json:"sourceId"`
 	// Program ID
@@ -173,7 +175,7 @@ type DeliveryOrderPayment struct {
 	// Payment type kind
 	PaymentTypeKind string `json:"paymentTypeKind"`
 	// Payment sum
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 	// Payment type ID
 	PaymentTypeId uuid.UUID `json:"paymentTypeId"`
 	// Is processed externally flag
@@ -193,7 +195,7 @@ type DeliveryOrderTip struct {
 	// Tips type ID
 	TipsTypeId uuid.UUID `json:"tipsTypeId"`
 	// Tip sum
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 	// Payment type ID
 	PaymentTypeId uuid.UUID `json:"paymentTypeId"`
 	// Is processed externally flag
@@ -217,7 +219,7 @@ type DeliveryDiscount struct {
 	// Discount type
 	DiscountType *DeliveryDiscountType `json:"discountType,omitempty"`
 	// Sum
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 	// Selective positions
 	SelectivePositions []uuid.UUID `json:"selectivePositions"`
 	// Selective positions with sum
@@ -237,7 +239,7 @@ type DeliverySelectivePositionWithSum struct {
 	// Position ID
 	PositionID uuid.UUID `json:"positionId"`
 	// Sum
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 }
 
 // DeliveryDiscountsInfo represents discounts information for delivery
@@ -280,15 +282,11 @@ type DeliveryExternalData struct {
 	IsPublic bool `json:"isPublic"`
 }
 
+var deliveryCreateEndpoint = NewEndpoint[DeliveryCreateRequest, DeliveryCreateResponse](EndpointInfo{Path: "/api/1/deliveries/create", RequiresAuth: true})
+
 // DeliveryCreate Create a new delivery order
 //
 // iiko API: /api/1/deliveries/create
-func (c *Client) DeliveryCreate(req *DeliveryCreateRequest, opts ...Option) (*DeliveryCreateResponse, error) {
-	var response DeliveryCreateResponse
-
-	if err := c.post(true, "/api/1/deliveries/create", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) DeliveryCreate(ctx context.Context, req *DeliveryCreateRequest, opts ...Option) (*DeliveryCreateResponse, error) {
+	return deliveryCreateEndpoint.Call(ctx, c, req, opts...)
 }
@@ -0,0 +1,313 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DiagnosticStatus is the outcome of a diagnostic check.
+type DiagnosticStatus string
+
+const (
+	// DiagnosticOK means the check passed.
+	DiagnosticOK DiagnosticStatus = "ok"
+	// DiagnosticDegraded means iikoCloud answered, but orders may not flow:
+	// a terminal group is offline or webhooks are not set up as expected.
+	DiagnosticDegraded DiagnosticStatus = "degraded"
+	// DiagnosticFailed means an API call of the check failed.
+	DiagnosticFailed DiagnosticStatus = "failed"
+)
+
+// Diagnostic check names.
+const (
+	DiagnosticCheckToken          = "token"
+	DiagnosticCheckOrganizations  = "organizations"
+	DiagnosticCheckTerminalGroups = "terminal_groups"
+	DiagnosticCheckTerminalsAlive = "terminal_groups_is_alive"
+	DiagnosticCheckWebhooks       = "webhook_settings"
+)
+
+// DiagnosticCheck is the result of one step of Diagnose.
+type DiagnosticCheck struct {
+	Name   string           `json:"name"`
+	Status DiagnosticStatus `json:"status"`
+	// Latency is the total time of the API calls of the check.
+	Latency time.Duration `json:"-"`
+	// LatencyMS is Latency in whole milliseconds.
+	LatencyMS int64 `json:"latencyMs"`
+	// Error of a failed check.
+	Error string `json:"error,omitempty"`
+	// Problems found by a degraded check, readable by support staff.
+	Problems []string `json:"problems,omitempty"`
+}
+
+// TerminalGroupDiagnostic is the state of a terminal group found by Diagnose.
+type TerminalGroupDiagnostic struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	IsAlive bool      `json:"isAlive"`
+}
+
+// WebhookDiagnostic is the webhook configuration of an organization found by Diagnose.
+type WebhookDiagnostic struct {
+	URI string `json:"uri"`
+	// Whether delivery order events are sent to URI.
+	DeliveryOrders bool `json:"deliveryOrders"`
+	// Whether the settings match the expectations given to Diagnose.
+	Matches bool `json:"matches"`
+}
+
+// OrganizationDiagnostic is what Diagnose found about an organization.
+type OrganizationDiagnostic struct {
+	ID             uuid.UUID                 `json:"id"`
+	Name           string                    `json:"name"`
+	TerminalGroups []TerminalGroupDiagnostic `json:"terminalGroups"`
+	Webhook        *WebhookDiagnostic        `json:"webhook,omitempty"`
+}
+
+// DiagnosticReport is the result of Diagnose. It is JSON encodable, e.g. for a
+// /healthz endpoint.
+type DiagnosticReport struct {
+	// Status is the worst status of Checks.
+	Status    DiagnosticStatus `json:"status"`
+	StartedAt time.Time        `json:"startedAt"`
+	Duration  time.Duration    `json:"-"`
+	// DurationMS is Duration in whole milliseconds.
+	DurationMS int64 `json:"durationMs"`
+	// TokenExpiresAt is when the access token used by the checks expires.
+	TokenExpiresAt time.Time                `json:"tokenExpiresAt"`
+	Organizations  []OrganizationDiagnostic `json:"organizations"`
+	Checks         []DiagnosticCheck        `json:"checks"`
+}
+
+// Healthy reports whether every check passed.
+func (r *DiagnosticReport) Healthy() bool {
+	return r.Status == DiagnosticOK
+}
+
+// DiagnoseOption sets an expectation checked by Diagnose.
+type DiagnoseOption func(*diagnoseConfig)
+
+type diagnoseConfig struct {
+	webhookURI       string
+	webhookAuthToken string
+	organizationIDs  []uuid.UUID
+}
+
+// DiagnoseWebhook makes Diagnose check that every organization sends webhooks
+// to uri with authToken. An empty authToken is not checked.
+func DiagnoseWebhook(uri, authToken string) DiagnoseOption {
+	return func(cfg *diagnoseConfig) {
+		cfg.webhookURI = uri
+		cfg.webhookAuthToken = authToken
+	}
+}
+
+// DiagnoseOrganizations limits Diagnose to the given organizations. By default
+// every organization available to the apiLogin is checked.
+func DiagnoseOrganizations(ids ...uuid.UUID) DiagnoseOption {
+	return func(cfg *diagnoseConfig) {
+		cfg.organizationIDs = ids
+	}
+}
+
+// Diagnose runs a health check of the integration with iikoCloud: it checks
+// the access token with an authorized call, lists the organizations, checks that their terminal groups
+// are alive and that webhooks are set up, measuring the latency of each step.
+//
+// Failures are reported in the DiagnosticReport; the returned error is
+// non-nil only if ctx is done. Steps that depend on a failed one are skipped.
+func (c *Client) Diagnose(ctx context.Context, opts ...DiagnoseOption) (*DiagnosticReport, error) {
+	var cfg diagnoseConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	report := &DiagnosticReport{StartedAt: time.Now()}
+	defer func() {
+		report.Duration = time.Since(report.StartedAt)
+		report.DurationMS = report.Duration.Milliseconds()
+		report.Status = DiagnosticOK
+		for _, check := range report.Checks {
+			if statusRank(check.Status) > statusRank(report.Status) {
+				report.Status = check.Status
+			}
+		}
+	}()
+
+	// The organizations list is the authorized call that checks the token:
+	// Client re-authenticates only if iikoCloud rejects the current token,
+	// so health checks do not spend the apiLogin's token requests. Its
+	// latency is counted in the token check.
+	var (
+		orgs    *OrganizationsResponse
+		orgsErr error
+	)
+	ok := report.check(DiagnosticCheckToken, func(check *DiagnosticCheck) error {
+		orgs, orgsErr = c.Organizations(ctx, &OrganizationsRequest{OrganizationIDs: cfg.organizationIDs})
+		if orgsErr != nil && !tokenAccepted(orgsErr) {
+			return orgsErr
+		}
+		report.TokenExpiresAt = c.TokenExpiresAt()
+		return nil
+	})
+	if !ok {
+		return report, ctx.Err()
+	}
+
+	ok = report.check(DiagnosticCheckOrganizations, func(check *DiagnosticCheck) error {
+		if orgsErr != nil {
+			return orgsErr
+		}
+		for _, org := range orgs.Organizations {
+			report.Organizations = append(report.Organizations, OrganizationDiagnostic{ID: org.ID, Name: org.Name})
+		}
+		if len(report.Organizations) == 0 {
+			check.Problems = append(check.Problems, "no organizations are available to the apiLogin")
+		}
+		return nil
+	})
+	if !ok || len(report.Organizations) == 0 {
+		return report, ctx.Err()
+	}
+
+	orgIDs := make([]uuid.UUID, len(report.Organizations))
+	byID := make(map[uuid.UUID]*OrganizationDiagnostic, len(report.Organizations))
+	for i := range report.Organizations {
+		orgIDs[i] = report.Organizations[i].ID
+		byID[orgIDs[i]] = &report.Organizations[i]
+	}
+
+	var terminalGroupIDs []uuid.UUID
+	ok = report.check(DiagnosticCheckTerminalGroups, func(check *DiagnosticCheck) error {
+		res, err := c.TerminalGroups(ctx, &TerminalGroupsRequest{OrganizationIDs: orgIDs})
+		if err != nil {
+			return err
+		}
+		for _, group := range res.TerminalGroups {
+			for _, item := range group.Items {
+				org, ok := byID[item.OrganizationID]
+				if !ok {
+					continue
+				}
+				org.TerminalGroups = append(org.TerminalGroups, TerminalGroupDiagnostic{ID: item.ID, Name: item.Name})
+				terminalGroupIDs = append(terminalGroupIDs, item.ID)
+			}
+		}
+		for _, org := range report.Organizations {
+			if len(org.TerminalGroups) == 0 {
+				check.Problems = append(check.Problems, fmt.Sprintf("organization %q has no enabled terminal groups", org.Name))
+			}
+		}
+		return nil
+	})
+
+	if ok && len(terminalGroupIDs) > 0 {
+		report.check(DiagnosticCheckTerminalsAlive, func(check *DiagnosticCheck) error {
+			res, err := c.TerminalGroupsIsAlive(ctx, &TerminalGroupsIsAliveRequest{
+				OrganizationIDs:  orgIDs,
+				TerminalGroupIDs: terminalGroupIDs,
+			})
+			if err != nil {
+				return err
+			}
+			alive := make(map[uuid.UUID]bool, len(res.IsAliveStatus))
+			for _, status := range res.IsAliveStatus {
+				alive[status.TerminalGroupID] = status.IsAlive
+			}
+			for _, org := range report.Organizations {
+				for i := range org.TerminalGroups {
+					group := &org.TerminalGroups[i]
+					group.IsAlive = alive[group.ID]
+					if !group.IsAlive {
+						check.Problems = append(check.Problems, fmt.Sprintf("terminal group %q of organization %q is offline", group.Name, org.Name))
+					}
+				}
+			}
+			return nil
+		})
+	}
+
+	report.check(DiagnosticCheckWebhooks, func(check *DiagnosticCheck) error {
+		for i := range report.Organizations {
+			org := &report.Organizations[i]
+			res, err := c.WebhookSettings(ctx, &WebhookSettingsRequest{OrganizationId: org.ID})
+			if err != nil {
+				return fmt.Errorf("organization %q: %w", org.Name, err)
+			}
+			problems := webhookProblems(org.Name, res, cfg)
+			org.Webhook = &WebhookDiagnostic{
+				URI:            res.WebHooksUri,
+				DeliveryOrders: res.WebHooksFilter.DeliveryOrderFilter != nil,
+				Matches:        len(problems) == 0,
+			}
+			check.Problems = append(check.Problems, problems...)
+		}
+		return nil
+	})
+
+	return report, ctx.Err()
+}
+
+// check runs fn as the check name and appends its result to r. It reports
+// whether fn succeeded, even if it found problems.
+func (r *DiagnosticReport) check(name string, fn func(check *DiagnosticCheck) error) bool {
+	check := DiagnosticCheck{Name: name, Status: DiagnosticOK}
+	start := time.Now()
+	err := fn(&check)
+	check.Latency = time.Since(start)
+	check.LatencyMS = check.Latency.Milliseconds()
+
+	switch {
+	case err != nil:
+		check.Status = DiagnosticFailed
+		check.Error = err.Error()
+	case len(check.Problems) > 0:
+		check.Status = DiagnosticDegraded
+	}
+
+	r.Checks = append(r.Checks, check)
+	return err == nil
+}
+
+// tokenAccepted reports whether the failed API call err got past
+// authentication, i.e. iikoCloud answered it with an error of its own.
+func tokenAccepted(err error) bool {
+	var errResp *ErrorResponse
+	return errors.As(err, &errResp) &&
+		errResp.Endpoint != accessTokenEndpoint.Info().Path &&
+		errResp.StatusCode != http.StatusUnauthorized
+}
+
+func webhookProblems(orgName string, settings *WebhookSettingsResponse, cfg diagnoseConfig) []string {
+	var problems []string
+	switch {
+	case settings.WebHooksUri == "":
+		problems = append(problems, fmt.Sprintf("organization %q has no webhook URI", orgName))
+	case cfg.webhookURI != "" && settings.WebHooksUri != cfg.webhookURI:
+		problems = append(problems, fmt.Sprintf("organization %q sends webhooks to %s instead of %s", orgName, settings.WebHooksUri, cfg.webhookURI))
+	}
+	if cfg.webhookAuthToken != "" && settings.AuthToken != cfg.webhookAuthToken {
+		problems = append(problems, fmt.Sprintf("organization %q sends webhooks with another auth token", orgName))
+	}
+	if settings.WebHooksFilter.DeliveryOrderFilter == nil {
+		problems = append(problems, fmt.Sprintf("organization %q does not send delivery order webhooks", orgName))
+	}
+	return problems
+}
+
+func statusRank(s DiagnosticStatus) int {
+	switch s {
+	case DiagnosticDegraded:
+		return 1
+	case DiagnosticFailed:
+		return 2
+	default:
+		return 0
+	}
+}
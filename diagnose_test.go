@@ -0,0 +1,221 @@
+package iiko
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	diagOrgID   = "7bc05553-4b68-44e8-b7bc-37be63c6d9e9"
+	diagGroupID = "a6b4f24c-5b5f-4f8e-a5ee-1b5b4c5b7e1d"
+)
+
+// diagState configures the iikoCloud stub of newDiagServer.
+type diagState struct {
+	noOrganizations     bool
+	offline             bool
+	webhookURI          string
+	revokedToken        bool // the first token is rejected with 401
+	organizationsStatus int  // status of /api/1/organizations, 200 if zero
+}
+
+func newDiagServer(t *testing.T, state diagState) *testServer {
+	t.Helper()
+
+	return newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/1/organizations":
+			switch {
+			case state.revokedToken && r.Header.Get("Authorization") == "Bearer token-1":
+				writeJSON(w, http.StatusUnauthorized, `{"errorDescription":"Token is expired or invalid"}`)
+			case state.organizationsStatus != 0:
+				writeJSON(w, state.organizationsStatus, `{"errorDescription":"boom"}`)
+			case state.noOrganizations:
+				writeJSON(w, http.StatusOK, `{"organizations":[]}`)
+			default:
+				writeJSON(w, http.StatusOK, `{"organizations":[{"id":"`+diagOrgID+`","name":"Pizza"}]}`)
+			}
+		case "/api/1/terminal_groups":
+			writeJSON(w, http.StatusOK, `{"terminalGroups":[{"organizationId":"`+diagOrgID+`","items":[{"id":"`+diagGroupID+`","organizationId":"`+diagOrgID+`","name":"Kitchen"}]}]}`)
+		case "/api/1/terminal_groups/is_alive":
+			writeJSON(w, http.StatusOK, fmt.Sprintf(`{"isAliveStatus":[{"terminalGroupId":"%s","organizationId":"%s","isAlive":%t}]}`, diagGroupID, diagOrgID, !state.offline))
+		case "/api/1/webhooks/settings":
+			writeJSON(w, http.StatusOK, `{"webHooksUri":"`+state.webhookURI+`","authToken":"secret","webHooksFilter":{"deliveryOrderFilter":{"errors":true}}}`)
+		default:
+			writeJSON(w, http.StatusNotFound, `{}`)
+		}
+	})
+
+}
+
+func TestDiagnose(t *testing.T) {
+	const webhookURI = "https://example.com/iiko"
+
+	tests := []struct {
+		name         string
+		state        diagState
+		rejectTokens bool
+		opts         []DiagnoseOption
+		wantStatus   DiagnosticStatus
+		wantChecks   map[string]DiagnosticStatus
+		// wantTokens is how many access tokens Diagnose requests.
+		wantTokens int32
+	}{
+		{
+			name:       "healthy",
+			state:      diagState{webhookURI: webhookURI},
+			opts:       []DiagnoseOption{DiagnoseWebhook(webhookURI, "secret")},
+			wantStatus: DiagnosticOK,
+			wantChecks: map[string]DiagnosticStatus{
+				DiagnosticCheckToken:          DiagnosticOK,
+				DiagnosticCheckOrganizations:  DiagnosticOK,
+				DiagnosticCheckTerminalGroups: DiagnosticOK,
+				DiagnosticCheckTerminalsAlive: DiagnosticOK,
+				DiagnosticCheckWebhooks:       DiagnosticOK,
+			},
+		},
+		{
+			name:       "revoked token",
+			state:      diagState{webhookURI: webhookURI, revokedToken: true},
+			wantStatus: DiagnosticOK,
+			wantChecks: map[string]DiagnosticStatus{
+				DiagnosticCheckToken:          DiagnosticOK,
+				DiagnosticCheckOrganizations:  DiagnosticOK,
+				DiagnosticCheckTerminalGroups: DiagnosticOK,
+				DiagnosticCheckTerminalsAlive: DiagnosticOK,
+				DiagnosticCheckWebhooks:       DiagnosticOK,
+			},
+			wantTokens: 1,
+		},
+		{
+			name:         "revoked token and apiLogin rejected",
+			state:        diagState{webhookURI: webhookURI, revokedToken: true},
+			rejectTokens: true,
+			wantStatus:   DiagnosticFailed,
+			wantChecks:   map[string]DiagnosticStatus{DiagnosticCheckToken: DiagnosticFailed},
+			wantTokens:   1,
+		},
+		{
+			name:       "organizations fail",
+			state:      diagState{organizationsStatus: http.StatusBadRequest},
+			wantStatus: DiagnosticFailed,
+			wantChecks: map[string]DiagnosticStatus{
+				DiagnosticCheckToken:         DiagnosticOK,
+				DiagnosticCheckOrganizations: DiagnosticFailed,
+			},
+		},
+		{
+			name:       "no organizations",
+			state:      diagState{noOrganizations: true},
+			wantStatus: DiagnosticDegraded,
+			wantChecks: map[string]DiagnosticStatus{
+				DiagnosticCheckToken:         DiagnosticOK,
+				DiagnosticCheckOrganizations: DiagnosticDegraded,
+			},
+		},
+		{
+			name:       "terminal offline and other webhook",
+			state:      diagState{offline: true, webhookURI: "https://other.example.com"},
+			opts:       []DiagnoseOption{DiagnoseWebhook(webhookURI, "")},
+			wantStatus: DiagnosticDegraded,
+			wantChecks: map[string]DiagnosticStatus{
+				DiagnosticCheckToken:          DiagnosticOK,
+				DiagnosticCheckOrganizations:  DiagnosticOK,
+				DiagnosticCheckTerminalGroups: DiagnosticOK,
+				DiagnosticCheckTerminalsAlive: DiagnosticDegraded,
+				DiagnosticCheckWebhooks:       DiagnosticDegraded,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newDiagServer(t, tt.state)
+			c := newTestClient(t, s)
+			s.rejectTokens.Store(tt.rejectTokens)
+			before := s.tokenRequests.Load()
+
+			report, err := c.Diagnose(context.Background(), tt.opts...)
+			if err != nil {
+				t.Fatalf("Diagnose: %v", err)
+			}
+			if report.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v; checks: %+v", report.Status, tt.wantStatus, report.Checks)
+			}
+			if len(report.Checks) != len(tt.wantChecks) {
+				t.Errorf("got %d checks, want %d: %+v", len(report.Checks), len(tt.wantChecks), report.Checks)
+			}
+			for _, check := range report.Checks {
+				if want := tt.wantChecks[check.Name]; check.Status != want {
+					t.Errorf("check %s = %v, want %v (%s %v)", check.Name, check.Status, want, check.Error, check.Problems)
+				}
+			}
+
+			// A token iikoCloud accepts is not renewed.
+			if got := s.tokenRequests.Load() - before; got != tt.wantTokens {
+				t.Errorf("Diagnose made %d token requests, want %d", got, tt.wantTokens)
+			}
+			if tt.rejectTokens && s.callCount("/api/1/terminal_groups") != 0 {
+				t.Error("Diagnose went on after the token check failed")
+			}
+		})
+	}
+}
+
+func TestDiagnosticReportJSON(t *testing.T) {
+	report := &DiagnosticReport{
+		Status:     DiagnosticOK,
+		Duration:   1500 * time.Millisecond,
+		DurationMS: 1500,
+		Checks: []DiagnosticCheck{
+			{Name: DiagnosticCheckToken, Status: DiagnosticOK, Latency: 42 * time.Millisecond, LatencyMS: 42},
+		},
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+		bad  string
+	}{
+		{"duration", `"durationMs":1500`, `"duration":`},
+		{"latency", `"latencyMs":42`, `"latency":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("%s has no %s", data, tt.want)
+			}
+			if strings.Contains(string(data), tt.bad) {
+				t.Errorf("%s still has %s", data, tt.bad)
+			}
+		})
+	}
+}
+
+func TestDiagnoseMeasuresMilliseconds(t *testing.T) {
+	s := newDiagServer(t, diagState{})
+	c := newTestClient(t, s)
+
+	report, err := c.Diagnose(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.DurationMS != report.Duration.Milliseconds() {
+		t.Errorf("DurationMS = %d, want %d", report.DurationMS, report.Duration.Milliseconds())
+	}
+	for _, check := range report.Checks {
+		if check.LatencyMS != check.Latency.Milliseconds() {
+			t.Errorf("%s: LatencyMS = %d, want %d", check.Name, check.LatencyMS, check.Latency.Milliseconds())
+		}
+	}
+}
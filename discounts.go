@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -39,7 +41,7 @@ type DiscountCardTypeInfo struct {
 	CanBeAppliedSelectively bool `json:"canBeAppliedSelectively"`
 
 	// Minimum order amount required for discount application. If order amount is less than specified threshold, discount does not apply.
-	MinOrderSum float64 `json:"minOrderSum"`
+	MinOrderSum Money `json:"minOrderSum"`
 
 	// Enum: "Percent" "FlexibleSum" "FixedSum"`
 	// Can be obtained by /api/1/discounts operation. [required]
@@ -47,7 +49,7 @@ type DiscountCardTypeInfo struct {
 
 	// Fixed amount.
 	// Triggers if fixed amount has been specified. [required]
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 
 	// Can be applied by card No.
 	// If true, it's enough to enter discount card No. (card swiping not required) [required]
@@ -89,15 +91,11 @@ type DiscountsResponse struct {
 	Discounts []Discount `json:"discounts"`
 }
 
+var discountsEndpoint = NewEndpoint[DiscountsRequest, DiscountsResponse](EndpointInfo{Path: "/api/1/discounts", RequiresAuth: true, Idempotent: true})
+
 // Discounts / surcharges.
 //
 // iiko API: /api/1/discounts
-func (c *Client) Discounts(req *DiscountsRequest, opts ...Option) (*DiscountsResponse, error) {
-	var response DiscountsResponse
-
-	if err := c.post(true, "/api/1/discounts", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) Discounts(ctx context.Context, req *DiscountsRequest, opts ...Option) (*DiscountsResponse, error) {
+	return discountsEndpoint.Call(ctx, c, req, opts...)
 }
@@ -0,0 +1,291 @@
+package iiko
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DriftKind is the kind of difference between an iikoCloud response and the
+// model of the library.
+type DriftKind string
+
+const (
+	// DriftUnknownField is a response field the model has no field for.
+	DriftUnknownField DriftKind = "unknown_field"
+	// DriftTypeMismatch is a response value the model field cannot hold,
+	// e.g. the string "false" for a bool.
+	DriftTypeMismatch DriftKind = "type_mismatch"
+)
+
+// SchemaDrift is one difference between a response and the model.
+type SchemaDrift struct {
+	Kind DriftKind
+	// Path of the value in the response, e.g. "orders[].order.items[].price".
+	// "[]" stands for the elements of an array and "{}" for the values of an object decoded into a map.
+	Path string
+	// Go type of the model field; empty for unknown fields.
+	GoType string
+	// JSON type of the value: "object", "array", "string", "number" or "bool".
+	// Values are not reported, since they may contain personal data.
+	JSONType string
+}
+
+// DriftReport lists the differences found in a response. Each path is reported
+// once per response, even if it occurs in every element of an array.
+type DriftReport struct {
+	Endpoint string
+	Drifts   []SchemaDrift
+}
+
+// DriftObserver is implemented by a MetricsCollector that counts schema drift.
+// PrometheusMetrics implements it.
+type DriftObserver interface {
+	ObserveSchemaDrift(endpoint string, kind DriftKind, path string)
+}
+
+// WithDriftDetection makes the Client compare every successful response with
+// its model and report unknown fields and type mismatches to fn, and to the
+// MetricsCollector if it implements DriftObserver. fn may be nil.
+//
+// With drift detection a value of the wrong type no longer fails the call: the
+// field is left zero and the mismatch is reported. Values rejected by the
+// library's own types, e.g. a malformed Time, still fail it.
+func WithDriftDetection(fn func(ctx context.Context, report *DriftReport)) ClientOption {
+	return func(c *Client) {
+		c.drift = &driftDetector{handler: fn}
+	}
+}
+
+type driftDetector struct {
+	handler func(ctx context.Context, report *DriftReport)
+}
+
+// tolerates reports whether the decoding error err is a drift that must not fail the call.
+func (d *driftDetector) tolerates(err error) bool {
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &typeErr)
+}
+
+// detectDrift compares the response of call with its model and reports the differences.
+func (c *Client) detectDrift(ctx context.Context, call *Call) {
+	if call.Response == nil {
+		return
+	}
+
+	drifts := make(map[SchemaDrift]struct{})
+	compareDrift("", call.ResponseBody, reflect.TypeOf(call.Response), drifts)
+	if len(drifts) == 0 {
+		return
+	}
+
+	report := &DriftReport{Endpoint: call.Endpoint}
+	for drift := range drifts {
+		report.Drifts = append(report.Drifts, drift)
+	}
+	sort.Slice(report.Drifts, func(i, j int) bool {
+		a, b := report.Drifts[i], report.Drifts[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Kind < b.Kind
+	})
+
+	if observer, ok := c.metrics.(DriftObserver); ok {
+		for _, drift := range report.Drifts {
+			observer.ObserveSchemaDrift(call.Endpoint, drift.Kind, drift.Path)
+		}
+	}
+	if c.drift.handler != nil {
+		c.drift.handler(ctx, report)
+	}
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// compareDrift adds to drifts the differences between the JSON value raw at
+// path and the Go type t it is decoded into.
+func compareDrift(path string, raw []byte, t reflect.Type, drifts map[SchemaDrift]struct{}) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	mismatch := func() {
+		drifts[SchemaDrift{Kind: DriftTypeMismatch, Path: path, GoType: t.String(), JSONType: jsonType(raw)}] = struct{}{}
+	}
+
+	// Types with their own decoding, and scalars, are checked by decoding
+	// the value exactly as the response decoder does.
+	ptr := reflect.PointerTo(t)
+	custom := ptr.Implements(jsonUnmarshalerType) || ptr.Implements(textUnmarshalerType)
+	if custom || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8) {
+		if json.Unmarshal(raw, reflect.New(t).Interface()) != nil {
+			mismatch()
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return
+
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if raw[0] != '{' || json.Unmarshal(raw, &object) != nil {
+			mismatch()
+			return
+		}
+		fields := jsonFields(t)
+		for name, value := range object {
+			field, ok := fields.lookup(name)
+			if !ok {
+				drifts[SchemaDrift{Kind: DriftUnknownField, Path: joinPath(path, name), JSONType: jsonType(value)}] = struct{}{}
+				continue
+			}
+			if !field.quoted {
+				compareDrift(joinPath(path, field.name), value, field.typ, drifts)
+			}
+		}
+
+	case reflect.Map:
+		var object map[string]json.RawMessage
+		if raw[0] != '{' || json.Unmarshal(raw, &object) != nil {
+			mismatch()
+			return
+		}
+		for _, value := range object {
+			compareDrift(path+"{}", value, t.Elem(), drifts)
+		}
+
+	case reflect.Slice, reflect.Array:
+		var elems []json.RawMessage
+		if raw[0] != '[' || json.Unmarshal(raw, &elems) != nil {
+			mismatch()
+			return
+		}
+		for _, elem := range elems {
+			compareDrift(path+"[]", elem, t.Elem(), drifts)
+		}
+
+	default:
+		if json.Unmarshal(raw, reflect.New(t).Interface()) != nil {
+			mismatch()
+		}
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func jsonType(raw []byte) string {
+	switch raw[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	default:
+		return "number"
+	}
+}
+
+// jsonField is a struct field as encoding/json sees it.
+type jsonField struct {
+	name string
+	typ  reflect.Type
+	// quoted fields use the ",string" option and are not compared.
+	quoted bool
+}
+
+// jsonFieldSet holds the JSON fields of a struct type.
+type jsonFieldSet struct {
+	byName map[string]jsonField
+}
+
+// lookup finds the field for a JSON key, preferring an exact match and
+// falling back to a case-insensitive one like encoding/json.
+func (s jsonFieldSet) lookup(name string) (jsonField, bool) {
+	if field, ok := s.byName[name]; ok {
+		return field, true
+	}
+	for fieldName, field := range s.byName {
+		if strings.EqualFold(fieldName, name) {
+			return field, true
+		}
+	}
+	return jsonField{}, false
+}
+
+var jsonFieldCache sync.Map // reflect.Type -> jsonFieldSet
+
+func jsonFields(t reflect.Type) jsonFieldSet {
+	if cached, ok := jsonFieldCache.Load(t); ok {
+		return cached.(jsonFieldSet)
+	}
+
+	set := jsonFieldSet{byName: make(map[string]jsonField)}
+	collectJSONFields(t, set.byName, 0)
+	jsonFieldCache.Store(t, set)
+	return set
+}
+
+// collectJSONFields adds the fields of t to fields. Fields of embedded structs
+// are added after the outer ones, so that the shallower field wins.
+func collectJSONFields(t reflect.Type, fields map[string]jsonField, depth int) {
+	if depth > 8 {
+		return
+	}
+
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded = append(embedded, ft)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := fields[name]; !ok {
+			fields[name] = jsonField{name: name, typ: f.Type, quoted: strings.Contains(","+opts+",", ",string,")}
+		}
+	}
+
+	for _, et := range embedded {
+		collectJSONFields(et, fields, depth+1)
+	}
+}
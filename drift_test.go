@@ -0,0 +1,216 @@
+package iiko
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type driftInner struct {
+	Price Money `json:"price"`
+	When  Time  `json:"when"`
+}
+
+type driftBase struct {
+	Base string `json:"base"`
+}
+
+type driftModel struct {
+	driftBase
+	ID      string                `json:"id"`
+	Enabled bool                  `json:"enabled"`
+	Count   int64                 `json:"count,string"`
+	Items   []driftInner          `json:"items"`
+	ByID    map[string]driftInner `json:"byId"`
+	Extra   interface{}           `json:"extra"`
+	Inner   *driftInner           `json:"inner"`
+	Skipped string                `json:"-"`
+}
+
+func TestCompareDrift(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []SchemaDrift
+	}{
+		{
+			name: "matching",
+			body: `{"base":"b","id":"1","enabled":true,"count":"5","items":[{"price":1.5,"when":"2024-01-02 10:11:12.000"}],"byId":{"a":{"price":"2"}},"extra":[1,"x"],"inner":null}`,
+		},
+		{
+			name: "case-insensitive key",
+			body: `{"ID":"1","Enabled":false}`,
+		},
+		{
+			name: "unknown fields",
+			body: `{"id":"1","newField":{"a":1},"items":[{"price":1,"discount":0.5},{"discount":1}],"-":"x"}`,
+			want: []SchemaDrift{
+				{Kind: DriftUnknownField, Path: "-", JSONType: "string"},
+				{Kind: DriftUnknownField, Path: "items[].discount", JSONType: "number"},
+				{Kind: DriftUnknownField, Path: "newField", JSONType: "object"},
+			},
+		},
+		{
+			name: "type mismatches",
+			body: `{"id":1,"enabled":"false","items":{},"byId":{"a":{"price":true}},"inner":{"when":"yesterday"}}`,
+			want: []SchemaDrift{
+				{Kind: DriftTypeMismatch, Path: "byId{}.price", GoType: "iiko.Money", JSONType: "bool"},
+				{Kind: DriftTypeMismatch, Path: "enabled", GoType: "bool", JSONType: "string"},
+				{Kind: DriftTypeMismatch, Path: "id", GoType: "string", JSONType: "number"},
+				{Kind: DriftTypeMismatch, Path: "inner.when", GoType: "iiko.Time", JSONType: "string"},
+				{Kind: DriftTypeMismatch, Path: "items", GoType: "[]iiko.driftInner", JSONType: "object"},
+			},
+		},
+		{
+			name: "quoted field is not compared",
+			body: `{"count":5}`,
+		},
+		{
+			name: "wrong top-level type",
+			body: `[]`,
+			want: []SchemaDrift{{Kind: DriftTypeMismatch, GoType: "iiko.driftModel", JSONType: "array"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drifts := make(map[SchemaDrift]struct{})
+			compareDrift("", []byte(tt.body), reflect.TypeOf(&driftModel{}), drifts)
+
+			want := make(map[SchemaDrift]struct{})
+			for _, d := range tt.want {
+				want[d] = struct{}{}
+			}
+			if !reflect.DeepEqual(drifts, want) {
+				t.Errorf("drifts = %+v, want %+v", drifts, tt.want)
+			}
+		})
+	}
+}
+
+type driftResponse struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+var driftEndpoint = NewEndpoint[struct{}, driftResponse](EndpointInfo{Path: "/api/1/test/drift", RequiresAuth: true, Idempotent: true})
+
+func TestDriftDetection(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		detect     bool
+		wantErr    bool
+		wantName   string
+		wantDrifts []SchemaDrift
+	}{
+		{
+			name:     "no drift",
+			body:     `{"name":"a","enabled":true}`,
+			detect:   true,
+			wantName: "a",
+		},
+		{
+			name:     "unknown field",
+			body:     `{"name":"a","phone":"+79990000000"}`,
+			detect:   true,
+			wantName: "a",
+			wantDrifts: []SchemaDrift{
+				{Kind: DriftUnknownField, Path: "phone", JSONType: "string"},
+			},
+		},
+		{
+			name:     "type mismatch is tolerated",
+			body:     `{"name":"a","enabled":"false","extra":1}`,
+			detect:   true,
+			wantName: "a",
+			wantDrifts: []SchemaDrift{
+				{Kind: DriftTypeMismatch, Path: "enabled", GoType: "bool", JSONType: "string"},
+				{Kind: DriftUnknownField, Path: "extra", JSONType: "number"},
+			},
+		},
+		{
+			name:    "type mismatch fails without detection",
+			body:    `{"name":"a","enabled":"false"}`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed JSON still fails",
+			body:    `{"name":`,
+			detect:  true,
+			wantErr: true,
+			wantDrifts: []SchemaDrift{
+				{Kind: DriftTypeMismatch, GoType: "iiko.driftResponse", JSONType: "object"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, tt.body)
+			})
+
+			var (
+				mu      sync.Mutex
+				reports []*DriftReport
+			)
+			metrics := NewPrometheusMetrics()
+			opts := []ClientOption{WithMetrics(metrics)}
+			if tt.detect {
+				opts = append(opts, WithDriftDetection(func(ctx context.Context, report *DriftReport) {
+					mu.Lock()
+					defer mu.Unlock()
+					reports = append(reports, report)
+				}))
+			}
+			c := newTestClient(t, s, opts...)
+
+			res, err := driftEndpoint.Call(context.Background(), c, &struct{}{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && res.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", res.Name, tt.wantName)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(tt.wantDrifts) == 0 {
+				if len(reports) != 0 {
+					t.Errorf("unexpected reports %+v", reports)
+				}
+				return
+			}
+			if len(reports) != 1 {
+				t.Fatalf("got %d reports, want 1", len(reports))
+			}
+			if reports[0].Endpoint != driftEndpoint.Info().Path {
+				t.Errorf("Endpoint = %q", reports[0].Endpoint)
+			}
+			if !reflect.DeepEqual(reports[0].Drifts, tt.wantDrifts) {
+				t.Errorf("Drifts = %+v, want %+v", reports[0].Drifts, tt.wantDrifts)
+			}
+
+			// Values are never reported, and the metrics count every drift.
+			data, _ := json.Marshal(reports[0])
+			if strings.Contains(string(data), "+7999") {
+				t.Errorf("report leaks a value: %s", data)
+			}
+			var text strings.Builder
+			if err := metrics.WriteText(&text); err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range tt.wantDrifts {
+				want := `iiko_schema_drift_total{endpoint="/api/1/test/drift",kind="` + string(d.Kind) + `",path="` + d.Path + `"} 1`
+				if !strings.Contains(text.String(), want) {
+					t.Errorf("metrics miss %s", want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package iiko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EndpointInfo describes an iikoCloud API method.
+type EndpointInfo struct {
+	// Path of the method, e.g. "/api/1/organizations".
+	Path string
+	// Whether the call carries the access token.
+	RequiresAuth bool
+	// Whether the call may be replayed without side effects. Non-idempotent
+	// endpoints are never retried unless the call is marked WithRetrySafe and
+	// are rate limited as mutations.
+	Idempotent bool
+	// iikoCloud API version. If zero, it is taken from Path.
+	APIVersion int
+}
+
+// Endpoint is a typed iikoCloud API method: requests of type Req, responses of type Resp.
+//
+// Declaring a method the library doesn't cover yet takes two lines:
+//
+//	var streetsByCity = iiko.NewEndpoint[StreetsByCityRequest, StreetsByCityResponse](
+//		iiko.EndpointInfo{Path: "/api/1/streets/by_city", RequiresAuth: true, Idempotent: true})
+//
+//	res, err := streetsByCity.Call(ctx, client, &StreetsByCityRequest{...})
+type Endpoint[Req, Resp any] struct {
+	info EndpointInfo
+}
+
+// NewEndpoint registers the endpoint described by info and returns its typed handle.
+// It panics if an endpoint with the same path but different info is already registered.
+func NewEndpoint[Req, Resp any](info EndpointInfo) *Endpoint[Req, Resp] {
+	if info.APIVersion == 0 {
+		info.APIVersion = apiVersion(info.Path)
+	}
+	registerEndpoint(info)
+	return &Endpoint[Req, Resp]{info: info}
+}
+
+// Info returns the endpoint metadata.
+func (e *Endpoint[Req, Resp]) Info() EndpointInfo {
+	return e.info
+}
+
+// Call performs the API method with c. It shares the whole transport of the
+// Client: auth, rate limiting, retries, middleware and typed errors.
+//
+// If the Client coalesces calls (see WithCoalescing), concurrent identical
+// calls may return the same *Resp.
+func (e *Endpoint[Req, Resp]) Call(ctx context.Context, c *Client, req *Req, opts ...Option) (*Resp, error) {
+	if c.coalescer != nil && c.coalescer.coalesces(e.info, opts) {
+		return e.coalescedCall(ctx, c, req)
+	}
+	return e.call(ctx, c, req, opts...)
+}
+
+func (e *Endpoint[Req, Resp]) coalescedCall(ctx context.Context, c *Client, req *Req) (*Resp, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	key, err := coalesceKey(e.info.Path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := c.coalescer.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return e.call(ctx, c, req)
+	})
+	if err != nil {
+		if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+			return nil, wrapTransportError(e.info.Path, err)
+		}
+		return nil, err
+	}
+	return v.(*Resp), nil
+}
+
+func (e *Endpoint[Req, Resp]) call(ctx context.Context, c *Client, req *Req, opts ...Option) (*Resp, error) {
+	var response Resp
+
+	if err := c.post(ctx, e.info.RequiresAuth, e.info.Path, req, &response, opts...); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+var endpoints = struct {
+	mu     sync.RWMutex
+	byPath map[string]EndpointInfo
+}{byPath: make(map[string]EndpointInfo)}
+
+func registerEndpoint(info EndpointInfo) {
+	endpoints.mu.Lock()
+	defer endpoints.mu.Unlock()
+
+	if registered, ok := endpoints.byPath[info.Path]; ok && registered != info {
+		panic(fmt.Sprintf("iiko: endpoint %s registered twice with different info", info.Path))
+	}
+	endpoints.byPath[info.Path] = info
+}
+
+// Endpoints returns every registered endpoint sorted by path: those built into
+// the library and those declared with NewEndpoint.
+func Endpoints() []EndpointInfo {
+	endpoints.mu.RLock()
+	defer endpoints.mu.RUnlock()
+
+	list := make([]EndpointInfo, 0, len(endpoints.byPath))
+	for _, info := range endpoints.byPath {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	return list
+}
+
+// LookupEndpoint returns the metadata of the endpoint registered under path.
+func LookupEndpoint(path string) (EndpointInfo, bool) {
+	endpoints.mu.RLock()
+	defer endpoints.mu.RUnlock()
+
+	info, ok := endpoints.byPath[path]
+	return info, ok
+}
+
+// isMutation reports whether endpoint changes state on the iiko side.
+// Unregistered endpoints, e.g. ones called through Post, are treated as reads.
+func isMutation(endpoint string) bool {
+	info, ok := LookupEndpoint(endpoint)
+	return ok && !info.Idempotent
+}
+
+// isIdempotent reports whether endpoint is registered as safe to replay.
+// Unregistered endpoints are not, since nothing is known about their side effects.
+func isIdempotent(endpoint string) bool {
+	info, ok := LookupEndpoint(endpoint)
+	return ok && info.Idempotent
+}
+
+// apiVersion extracts N from a "/api/N/..." path, 0 if there is none.
+func apiVersion(path string) int {
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return 0
+	}
+	version, _, _ := strings.Cut(rest, "/")
+	n, err := strconv.Atoi(version)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,191 @@
+package iiko
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAPIVersion(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/1/organizations", 1},
+		{"/api/2/menu/by_id", 2},
+		{"/api/x/organizations", 0},
+		{"/resto/api/1", 0},
+		{"/api/", 0},
+	}
+	for _, tt := range tests {
+		if got := apiVersion(tt.path); got != tt.want {
+			t.Errorf("apiVersion(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestNewEndpointRegisters(t *testing.T) {
+	tests := []struct {
+		name           string
+		info           EndpointInfo
+		wantVersion    int
+		wantMutation   bool
+		wantIdempotent bool
+	}{
+		{"read", EndpointInfo{Path: "/api/1/test/registry_read", RequiresAuth: true, Idempotent: true}, 1, false, true},
+		{"mutation", EndpointInfo{Path: "/api/1/test/registry_write", RequiresAuth: true}, 1, true, false},
+		{"explicit version", EndpointInfo{Path: "/test/registry_v3", APIVersion: 3, Idempotent: true}, 3, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEndpoint[struct{}, struct{}](tt.info)
+			if e.Info().APIVersion != tt.wantVersion {
+				t.Errorf("APIVersion = %d, want %d", e.Info().APIVersion, tt.wantVersion)
+			}
+			info, ok := LookupEndpoint(tt.info.Path)
+			if !ok || info != e.Info() {
+				t.Errorf("LookupEndpoint = %+v, %v", info, ok)
+			}
+			if isMutation(tt.info.Path) != tt.wantMutation || isIdempotent(tt.info.Path) != tt.wantIdempotent {
+				t.Errorf("isMutation %v, isIdempotent %v", isMutation(tt.info.Path), isIdempotent(tt.info.Path))
+			}
+
+			// Registering the same info again is allowed, e.g. from two packages.
+			NewEndpoint[struct{}, struct{}](tt.info)
+		})
+	}
+}
+
+func TestNewEndpointConflict(t *testing.T) {
+	NewEndpoint[struct{}, struct{}](EndpointInfo{Path: "/api/1/test/registry_conflict", Idempotent: true})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering different info under the same path did not panic")
+		}
+	}()
+	NewEndpoint[struct{}, struct{}](EndpointInfo{Path: "/api/1/test/registry_conflict"})
+}
+
+func TestUnregisteredEndpoint(t *testing.T) {
+	const path = "/api/1/test/never_registered"
+	if _, ok := LookupEndpoint(path); ok {
+		t.Fatal("endpoint is registered")
+	}
+	if isMutation(path) || isIdempotent(path) {
+		t.Error("an unregistered endpoint must be neither a mutation nor idempotent")
+	}
+}
+
+func TestEndpointsList(t *testing.T) {
+	list := Endpoints()
+	if !sort.SliceIsSorted(list, func(i, j int) bool { return list[i].Path < list[j].Path }) {
+		t.Error("Endpoints() is not sorted by path")
+	}
+
+	builtin := map[string]bool{
+		"/api/1/access_token":      true,
+		"/api/1/organizations":     true,
+		"/api/1/deliveries/create": false,
+	}
+	found := 0
+	for _, info := range list {
+		idempotent, ok := builtin[info.Path]
+		if !ok {
+			continue
+		}
+		found++
+		if info.Idempotent != idempotent || info.APIVersion != 1 {
+			t.Errorf("%s: %+v", info.Path, info)
+		}
+	}
+	if found != len(builtin) {
+		t.Errorf("found %d of %d built-in endpoints", found, len(builtin))
+	}
+}
+
+func TestEndpointCall(t *testing.T) {
+	type echoRequest struct {
+		Value string `json:"value"`
+	}
+	type echoResponse struct {
+		Echo string `json:"echo"`
+	}
+	echo := NewEndpoint[echoRequest, echoResponse](EndpointInfo{Path: "/api/1/test/echo", RequiresAuth: true, Idempotent: true})
+
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var req echoRequest
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &req)
+		if r.Header.Get("Authorization") == "" {
+			writeJSON(w, http.StatusUnauthorized, `{}`)
+			return
+		}
+		writeJSON(w, http.StatusOK, `{"echo":"`+req.Value+`"}`)
+	})
+	c := newTestClient(t, srv)
+
+	res, err := echo.Call(context.Background(), c, &echoRequest{Value: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Echo != "hi" {
+		t.Errorf("Echo = %q", res.Echo)
+	}
+}
+
+// TestAPIMatchesRegistry calls every API method of a Client and checks that
+// each one goes to a registered endpoint, and that every built-in endpoint
+// besides access_token is served by some API method.
+func TestAPIMatchesRegistry(t *testing.T) {
+	var last atomic.Value
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		last.Store(r.URL.Path)
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+	})
+	c := newTestClient(t, srv)
+
+	client := reflect.ValueOf(c)
+	api := reflect.TypeOf((*API)(nil)).Elem()
+	served := make(map[string]string)
+	for i := 0; i < api.NumMethod(); i++ {
+		m := api.Method(i)
+		last.Store("")
+
+		args := []reflect.Value{reflect.ValueOf(context.Background())}
+		for j := 1; j < m.Type.NumIn(); j++ {
+			if in := m.Type.In(j); in.Kind() == reflect.Pointer {
+				args = append(args, reflect.New(in.Elem()))
+			}
+		}
+		client.MethodByName(m.Name).Call(args)
+
+		path := last.Load().(string)
+		if path == "" {
+			t.Errorf("%s sent no request", m.Name)
+			continue
+		}
+		if _, ok := LookupEndpoint(path); !ok {
+			t.Errorf("%s calls unregistered %s", m.Name, path)
+		}
+		if other, ok := served[path]; ok {
+			t.Errorf("%s and %s both call %s", other, m.Name, path)
+		}
+		served[path] = m.Name
+	}
+
+	for _, info := range Endpoints() {
+		if info.Path == accessTokenEndpoint.Info().Path || strings.Contains(info.Path, "/test/") {
+			continue
+		}
+		if _, ok := served[info.Path]; !ok {
+			t.Errorf("%s is registered but no API method calls it", info.Path)
+		}
+	}
+}
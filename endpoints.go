@@ -1,11 +1,25 @@
 package iiko
 
-import "github.com/google/uuid"
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
 
 // ErrorResponse is a custom error type that represents iikoCloud API Error.
+// It is returned for every response with a status other than 200, including
+// those whose body is not iiko JSON, e.g. an HTML 502 page of a proxy.
+// See Is for matching it against failure classes like ErrRateLimited.
 type ErrorResponse struct {
 	// http.StatusCode of request.
 	StatusCode int `json:"-"`
+	// iiko API endpoint of the failed call.
+	Endpoint string `json:"-"`
+	// Headers of the response.
+	Header http.Header `json:"-"`
+	// Raw body of the response.
+	Body []byte `json:"-"`
 	// Operation ID [required]
 	CorrelationID uuid.UUID `json:"correlationId"`
 	// Error text [required]
@@ -16,5 +30,8 @@ type ErrorResponse struct {
 
 // Error ...
 func (e *ErrorResponse) Error() string {
+	if e.ErrorDescription == "" {
+		return fmt.Sprintf("iiko: %s: %d %s", e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	}
 	return e.ErrorDescription
 }
@@ -0,0 +1,199 @@
+package iiko
+
+// ErrorCategory groups ErrorInfoCode values by the part of an order they are about.
+type ErrorCategory string
+
+const (
+	ErrorCategoryCatalog      ErrorCategory = "Catalog"
+	ErrorCategoryPayment      ErrorCategory = "Payment"
+	ErrorCategoryDiscount     ErrorCategory = "Discount"
+	ErrorCategoryAddress      ErrorCategory = "Address"
+	ErrorCategoryCustomer     ErrorCategory = "Customer"
+	ErrorCategoryOrder        ErrorCategory = "Order"
+	ErrorCategoryCourier      ErrorCategory = "Courier"
+	ErrorCategoryTerminal     ErrorCategory = "Terminal"
+	ErrorCategoryOrganization ErrorCategory = "Organization"
+	ErrorCategoryInternal     ErrorCategory = "Internal"
+	ErrorCategoryUnknown      ErrorCategory = "Unknown"
+)
+
+// ErrorAction is what an order intake should do about a failed order.
+type ErrorAction string
+
+const (
+	// ErrorActionRetry: send the same order again later.
+	ErrorActionRetry ErrorAction = "Retry"
+	// ErrorActionReroute: send the order to another terminal group or organization.
+	ErrorActionReroute ErrorAction = "Reroute"
+	// ErrorActionReject: the order itself is invalid and must be fixed or rejected.
+	ErrorActionReject ErrorAction = "Reject"
+)
+
+// Language of human-readable error messages.
+type Language string
+
+const (
+	LanguageEN Language = "en"
+	LanguageRU Language = "ru"
+)
+
+type errorInfoClass struct {
+	category ErrorCategory
+	action   ErrorAction
+	// JSON path of the request field that caused the error, if any.
+	field string
+	en    string
+	ru    string
+}
+
+var errorInfoClasses = map[ErrorInfoCode]errorInfoClass{
+	"Common":                                        {ErrorCategoryUnknown, ErrorActionReject, "", "Order was rejected by iiko.", "Заказ отклонён iiko."},
+	"IllegalDeliveryStatus":                         {ErrorCategoryOrder, ErrorActionReject, "deliveryStatus", "Delivery status cannot be set.", "Недопустимый статус доставки."},
+	"CustomerNameNotSpecified":                      {ErrorCategoryCustomer, ErrorActionReject, "order.customer.name", "Customer name is not specified.", "Не указано имя клиента."},
+	"ProductNotFound":                               {ErrorCategoryCatalog, ErrorActionReject, "order.items.productId", "Product not found.", "Товар не найден."},
+	"MarketingSourceNotFound":                       {ErrorCategoryOrder, ErrorActionReject, "order.marketingSourceId", "Marketing source not found.", "Рекламный источник не найден."},
+	"PaymentTypeNotFound":                           {ErrorCategoryPayment, ErrorActionReject, "order.payments.paymentTypeId", "Payment type not found.", "Тип оплаты не найден."},
+	"ProductSizeNotFound":                           {ErrorCategoryCatalog, ErrorActionReject, "order.items.productSizeId", "Product size not found.", "Размер товара не найден."},
+	"ProductGroupNotFound":                          {ErrorCategoryCatalog, ErrorActionReject, "order.items.productGroupId", "Product group not found.", "Группа товаров не найдена."},
+	"OrderNotFound":                                 {ErrorCategoryOrder, ErrorActionReject, "orderId", "Order not found.", "Заказ не найден."},
+	"ConceptionNotFound":                            {ErrorCategoryOrder, ErrorActionReject, "order.conceptionId", "Conception not found.", "Концепция не найдена."},
+	"DuplicatedOrderId":                             {ErrorCategoryOrder, ErrorActionReject, "order.id", "Order with this ID already exists.", "Заказ с таким ID уже существует."},
+	"TerminalGroupIdNotDetermined":                  {ErrorCategoryTerminal, ErrorActionReroute, "terminalGroupId", "Terminal group could not be determined.", "Не удалось определить группу терминалов."},
+	"TerminalGroupUnregistered":                     {ErrorCategoryTerminal, ErrorActionReroute, "terminalGroupId", "Terminal group is not registered.", "Группа терминалов не зарегистрирована."},
+	"InvalidPhone":                                  {ErrorCategoryCustomer, ErrorActionReject, "order.phone", "Invalid phone number.", "Неверный номер телефона."},
+	"ModifierDuplicated":                            {ErrorCategoryCatalog, ErrorActionReject, "order.items.modifiers", "Modifier is duplicated.", "Модификатор указан повторно."},
+	"ProductCanBuyFromCashdesk":                     {ErrorCategoryCatalog, ErrorActionReject, "order.items.productId", "Product can only be sold at the cash desk.", "Товар продаётся только на кассе."},
+	"DeliveryOpinionMarkInvalid":                    {ErrorCategoryOrder, ErrorActionReject, "opinion.mark", "Invalid delivery opinion mark.", "Неверная оценка доставки."},
+	"WrongDeliveryStatusForOpinion":                 {ErrorCategoryOrder, ErrorActionReject, "", "Delivery status does not allow an opinion.", "Статус доставки не позволяет оставить отзыв."},
+	"OpinionCommentTooLong":                         {ErrorCategoryOrder, ErrorActionReject, "opinion.comment", "Opinion comment is too long.", "Слишком длинный комментарий к отзыву."},
+	"SurveyItemNotFound":                            {ErrorCategoryOrder, ErrorActionReject, "opinion.survey", "Survey item not found.", "Пункт опроса не найден."},
+	"PaymentTypeCanNotBeUsedAsExternal":             {ErrorCategoryPayment, ErrorActionReject, "order.payments.paymentTypeId", "Payment type cannot be used as external.", "Тип оплаты нельзя использовать как внешний."},
+	"AddressNotFound":                               {ErrorCategoryAddress, ErrorActionReject, "order.deliveryPoint.address", "Address not found.", "Адрес не найден."},
+	"HomeNotFound":                                  {ErrorCategoryAddress, ErrorActionReject, "order.deliveryPoint.address.house", "House not found.", "Дом не найден."},
+	"IikonetPaymentAdditionalDataCanNotBeParsed":    {ErrorCategoryPayment, ErrorActionReject, "order.payments.paymentAdditionalData", "Payment additional data cannot be parsed.", "Не удалось разобрать дополнительные данные оплаты."},
+	"IikonetPaymentExternalIdNotFound":              {ErrorCategoryPayment, ErrorActionReject, "order.payments.paymentAdditionalData", "External payment ID not found.", "Внешний ID оплаты не найден."},
+	"IikonetPaymentSumLessThanMarketingDiscount":    {ErrorCategoryPayment, ErrorActionReject, "order.payments.sum", "Payment sum is less than the marketing discount.", "Сумма оплаты меньше маркетинговой скидки."},
+	"DiscountCardNotFound":                          {ErrorCategoryDiscount, ErrorActionReject, "order.discountsInfo.card", "Discount card not found.", "Дисконтная карта не найдена."},
+	"DiscountCardTypeModeForbidden":                 {ErrorCategoryDiscount, ErrorActionReject, "order.discountsInfo.card", "Discount card type is not allowed.", "Тип дисконтной карты запрещён."},
+	"Iikocard5PaymentAdditionalDataCanNotBeParsed":  {ErrorCategoryPayment, ErrorActionReject, "order.payments.paymentAdditionalData", "Loyalty payment data cannot be parsed.", "Не удалось разобрать данные оплаты программой лояльности."},
+	"Iikocard5PaymentExternalIdNotFound":            {ErrorCategoryPayment, ErrorActionReject, "order.payments.paymentAdditionalData", "Loyalty payment external ID not found.", "Внешний ID оплаты программой лояльности не найден."},
+	"Iikocard5PaymentSumLessThanMarketingDiscount":  {ErrorCategoryPayment, ErrorActionReject, "order.payments.sum", "Loyalty payment sum is less than the marketing discount.", "Сумма оплаты программой лояльности меньше маркетинговой скидки."},
+	"Iikocard5PaymentCanNotCreateCustomData":        {ErrorCategoryPayment, ErrorActionRetry, "order.payments", "Loyalty payment data cannot be created.", "Не удалось создать данные оплаты программой лояльности."},
+	"CourierIdDoesNotExist":                         {ErrorCategoryCourier, ErrorActionReject, "employeeId", "Courier not found.", "Курьер не найден."},
+	"CourierDoesNotOwnOrder":                        {ErrorCategoryCourier, ErrorActionReject, "employeeId", "Order is not assigned to this courier.", "Заказ не назначен этому курьеру."},
+	"WrongDeliveryStatus":                           {ErrorCategoryOrder, ErrorActionReject, "deliveryStatus", "Wrong delivery status.", "Неверный статус доставки."},
+	"CanNotAssignCourierToOrder":                    {ErrorCategoryCourier, ErrorActionReject, "employeeId", "Courier cannot be assigned to the order.", "Невозможно назначить курьера на заказ."},
+	"UserNotFoundByExternalPassword":                {ErrorCategoryCourier, ErrorActionReject, "", "User not found by external password.", "Пользователь с таким внешним паролем не найден."},
+	"UserNotFound":                                  {ErrorCategoryCourier, ErrorActionReject, "", "User not found.", "Пользователь не найден."},
+	"Iikocard51PaymentAdditionalDataCanNotBeParsed": {ErrorCategoryPayment, ErrorActionReject, "order.payments.paymentAdditionalData", "Loyalty payment data cannot be parsed.", "Не удалось разобрать данные оплаты программой лояльности."},
+	"Iikocard51PaymentCredentialNotFound":           {ErrorCategoryPayment, ErrorActionReject, "order.payments.paymentAdditionalData.credential", "Loyalty payment credential not found.", "Идентификатор гостя для оплаты программой лояльности не найден."},
+	"Iikocard51PaymentSearchScopeNotFound":          {ErrorCategoryPayment, ErrorActionReject, "order.payments.paymentAdditionalData.searchScope", "Loyalty payment search scope not found.", "Область поиска гостя для оплаты программой лояльности не найдена."},
+	"ComboDuplicated":                               {ErrorCategoryCatalog, ErrorActionReject, "order.combos", "Combo is duplicated.", "Комбо указано повторно."},
+	"InvalidReferenceToCombo":                       {ErrorCategoryCatalog, ErrorActionReject, "order.items.comboInformation", "Item refers to an unknown combo.", "Позиция ссылается на неизвестное комбо."},
+	"InvalidComboItemsAmount":                       {ErrorCategoryCatalog, ErrorActionReject, "order.items.comboInformation", "Invalid amount of combo items.", "Неверное количество позиций комбо."},
+	"InvalidComboItemsGuest":                        {ErrorCategoryCatalog, ErrorActionReject, "order.items.comboInformation", "Combo items belong to different guests.", "Позиции комбо относятся к разным гостям."},
+	"InvalidReferenceToGuest":                       {ErrorCategoryOrder, ErrorActionReject, "order.guests", "Item refers to an unknown guest.", "Позиция ссылается на неизвестного гостя."},
+	"GuestDuplicated":                               {ErrorCategoryOrder, ErrorActionReject, "order.guests", "Guest is duplicated.", "Гость указан повторно."},
+	"GuestNameNotSpecified":                         {ErrorCategoryOrder, ErrorActionReject, "order.guests.name", "Guest name is not specified.", "Не указано имя гостя."},
+	"OrderTypeNotFound":                             {ErrorCategoryOrder, ErrorActionReject, "order.orderTypeId", "Order type not found.", "Тип заказа не найден."},
+	"OrderServiceTypeDoesNotMatchSelfServiceMode":   {ErrorCategoryOrder, ErrorActionReject, "order.orderServiceType", "Order service type does not match self-service mode.", "Тип обслуживания не соответствует режиму самовывоза."},
+	"DeliveryDateNotSpecified":                      {ErrorCategoryOrder, ErrorActionReject, "order.completeBefore", "Delivery date is not specified.", "Не указана дата доставки."},
+	"OrderStatusChangedInIikoFront":                 {ErrorCategoryOrder, ErrorActionReject, "", "Order status was changed in iikoFront.", "Статус заказа изменён в iikoFront."},
+	"PaymentAdditionalDataTooLong":                  {ErrorCategoryPayment, ErrorActionReject, "order.payments.paymentAdditionalData", "Payment additional data is too long.", "Слишком длинные дополнительные данные оплаты."},
+	"PaymentSumShouldBePositive":                    {ErrorCategoryPayment, ErrorActionReject, "order.payments.sum", "Payment sum must be positive.", "Сумма оплаты должна быть положительной."},
+	"DiscountSumNotSpecified":                       {ErrorCategoryDiscount, ErrorActionReject, "order.discountsInfo.discounts.sum", "Discount sum is not specified.", "Не указана сумма скидки."},
+	"InvalidDiscountItem":                           {ErrorCategoryDiscount, ErrorActionReject, "order.discountsInfo.discounts", "Invalid discount item.", "Неверная позиция скидки."},
+	"RequestProductPriceIsNotEqualToFrontPrice":     {ErrorCategoryCatalog, ErrorActionReject, "order.items.price", "Product price differs from the iikoFront price.", "Цена товара не совпадает с ценой в iikoFront."},
+	"OrderItemsNotExists":                           {ErrorCategoryOrder, ErrorActionReject, "order.items", "Order has no items.", "В заказе нет позиций."},
+	"EntityAlreadyInUse":                            {ErrorCategoryOrder, ErrorActionRetry, "", "Entity is locked by another operation.", "Объект заблокирован другой операцией."},
+	"DiscountItemPositionNotFound":                  {ErrorCategoryDiscount, ErrorActionReject, "order.discountsInfo.discounts.discountItems.positionId", "Discount refers to an unknown item position.", "Скидка ссылается на неизвестную позицию."},
+	"DiscountItemDuplicatePositions":                {ErrorCategoryDiscount, ErrorActionReject, "order.discountsInfo.discounts.discountItems.positionId", "Discount item positions are duplicated.", "Позиции скидки указаны повторно."},
+	"NonUnqiueOrderItemPosition":                    {ErrorCategoryOrder, ErrorActionReject, "order.items.positionId", "Order item position is not unique.", "Позиция заказа не уникальна."},
+	"EmptyOrderItemPosition":                        {ErrorCategoryOrder, ErrorActionReject, "order.items.positionId", "Order item position is empty.", "Не указана позиция заказа."},
+	"IncorrectOrderType":                            {ErrorCategoryOrder, ErrorActionReject, "order.orderTypeId", "Incorrect order type.", "Неверный тип заказа."},
+	"Incorrect":                                     {ErrorCategoryOrder, ErrorActionReject, "", "Order is incorrect.", "Заказ некорректен."},
+	"TerminalGroupDisabled":                         {ErrorCategoryTerminal, ErrorActionReroute, "terminalGroupId", "Terminal group is disabled.", "Группа терминалов отключена."},
+	"OrganizationUnregistered":                      {ErrorCategoryOrganization, ErrorActionReroute, "organizationId", "Organization is not registered.", "Организация не зарегистрирована."},
+	"OrganizationDisabled":                          {ErrorCategoryOrganization, ErrorActionReroute, "organizationId", "Organization is disabled.", "Организация отключена."},
+	"TooSmallDeliveryDate":                          {ErrorCategoryOrder, ErrorActionReject, "order.completeBefore", "Delivery date is too early.", "Слишком ранняя дата доставки."},
+	"IikoFrontTooOldVersion":                        {ErrorCategoryTerminal, ErrorActionReroute, "terminalGroupId", "iikoFront version is too old.", "Слишком старая версия iikoFront."},
+	"InternalServerError":                           {ErrorCategoryInternal, ErrorActionRetry, "", "iiko internal server error.", "Внутренняя ошибка сервера iiko."},
+	"UnknownError":                                  {ErrorCategoryUnknown, ErrorActionRetry, "", "Unknown iiko error.", "Неизвестная ошибка iiko."},
+}
+
+func (c ErrorInfoCode) class() (errorInfoClass, bool) {
+	class, ok := errorInfoClasses[c]
+	return class, ok
+}
+
+// Known reports whether the code is one of the codes documented by iiko.
+func (c ErrorInfoCode) Known() bool {
+	_, ok := c.class()
+	return ok
+}
+
+// Category returns the part of the order the error is about.
+func (c ErrorInfoCode) Category() ErrorCategory {
+	if class, ok := c.class(); ok {
+		return class.category
+	}
+	return ErrorCategoryUnknown
+}
+
+// Action returns what should be done with the order: retry, reroute or reject.
+// Unknown codes are rejected.
+func (c ErrorInfoCode) Action() ErrorAction {
+	if class, ok := c.class(); ok {
+		return class.action
+	}
+	return ErrorActionReject
+}
+
+// Retryable reports whether the same order may succeed if sent again.
+func (c ErrorInfoCode) Retryable() bool {
+	return c.Action() == ErrorActionRetry
+}
+
+// Field returns the JSON path of the request field that caused the error,
+// e.g. "order.items.productId", or "" when the error isn't about a single field.
+func (c ErrorInfoCode) Field() string {
+	class, _ := c.class()
+	return class.field
+}
+
+// Message returns a human-readable message in the given language.
+// Languages other than LanguageRU fall back to English.
+func (c ErrorInfoCode) Message(lang Language) string {
+	class, ok := c.class()
+	if !ok {
+		return ""
+	}
+	if lang == LanguageRU {
+		return class.ru
+	}
+	return class.en
+}
+
+// Category returns the part of the order the error is about.
+func (e ErrorInfo) Category() ErrorCategory { return e.Code.Category() }
+
+// Action returns what should be done with the order: retry, reroute or reject.
+func (e ErrorInfo) Action() ErrorAction { return e.Code.Action() }
+
+// Retryable reports whether the same order may succeed if sent again.
+func (e ErrorInfo) Retryable() bool { return e.Code.Retryable() }
+
+// Field returns the JSON path of the request field that caused the error.
+func (e ErrorInfo) Field() string { return e.Code.Field() }
+
+// LocalizedMessage returns a human-readable message in the given language.
+// For unknown codes it falls back to the description and message sent by iiko.
+func (e ErrorInfo) LocalizedMessage(lang Language) string {
+	if msg := e.Code.Message(lang); msg != "" {
+		return msg
+	}
+	if e.Description != "" {
+		return e.Description
+	}
+	return e.Message
+}
@@ -0,0 +1,78 @@
+package iiko
+
+import "testing"
+
+func TestErrorInfoCodeClass(t *testing.T) {
+	tests := []struct {
+		code      ErrorInfoCode
+		known     bool
+		category  ErrorCategory
+		action    ErrorAction
+		retryable bool
+		field     string
+	}{
+		{"ProductNotFound", true, ErrorCategoryCatalog, ErrorActionReject, false, "order.items.productId"},
+		{"PaymentTypeNotFound", true, ErrorCategoryPayment, ErrorActionReject, false, "order.payments.paymentTypeId"},
+		{"TerminalGroupUnregistered", true, ErrorCategoryTerminal, ErrorActionReroute, false, "terminalGroupId"},
+		{"InternalServerError", true, ErrorCategoryInternal, ErrorActionRetry, true, ""},
+		{"Common", true, ErrorCategoryUnknown, ErrorActionReject, false, ""},
+		{"SomethingNew", false, ErrorCategoryUnknown, ErrorActionReject, false, ""},
+		{"", false, ErrorCategoryUnknown, ErrorActionReject, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			c := tt.code
+			if c.Known() != tt.known || c.Category() != tt.category || c.Action() != tt.action ||
+				c.Retryable() != tt.retryable || c.Field() != tt.field {
+				t.Errorf("got known %v, %s, %s, retryable %v, field %q", c.Known(), c.Category(), c.Action(), c.Retryable(), c.Field())
+			}
+
+			info := ErrorInfo{Code: c}
+			if info.Category() != tt.category || info.Action() != tt.action || info.Retryable() != tt.retryable || info.Field() != tt.field {
+				t.Error("ErrorInfo disagrees with its code")
+			}
+		})
+	}
+}
+
+func TestErrorInfoClassesComplete(t *testing.T) {
+	categories := map[ErrorCategory]bool{
+		ErrorCategoryCatalog: true, ErrorCategoryPayment: true, ErrorCategoryDiscount: true,
+		ErrorCategoryAddress: true, ErrorCategoryCustomer: true, ErrorCategoryOrder: true,
+		ErrorCategoryCourier: true, ErrorCategoryTerminal: true, ErrorCategoryOrganization: true,
+		ErrorCategoryInternal: true, ErrorCategoryUnknown: true,
+	}
+	actions := map[ErrorAction]bool{ErrorActionRetry: true, ErrorActionReroute: true, ErrorActionReject: true}
+
+	for code, class := range errorInfoClasses {
+		if !categories[class.category] || !actions[class.action] {
+			t.Errorf("%s: category %q, action %q", code, class.category, class.action)
+		}
+		if class.en == "" || class.ru == "" {
+			t.Errorf("%s: missing message", code)
+		}
+	}
+}
+
+func TestErrorInfoLocalizedMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		info ErrorInfo
+		lang Language
+		want string
+	}{
+		{"english", ErrorInfo{Code: "ProductNotFound"}, LanguageEN, "Product not found."},
+		{"russian", ErrorInfo{Code: "ProductNotFound"}, LanguageRU, "Товар не найден."},
+		{"other language", ErrorInfo{Code: "ProductNotFound"}, "de", "Product not found."},
+		{"unknown code uses description", ErrorInfo{Code: "New", Message: "msg", Description: "desc"}, LanguageEN, "desc"},
+		{"unknown code uses message", ErrorInfo{Code: "New", Message: "msg"}, LanguageRU, "msg"},
+		{"nothing", ErrorInfo{Code: "New"}, LanguageEN, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.LocalizedMessage(tt.lang); got != tt.want {
+				t.Errorf("LocalizedMessage = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
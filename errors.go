@@ -0,0 +1,92 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Failure classes of iikoCloud API calls, to be matched with errors.Is:
+//
+//	if errors.Is(err, iiko.ErrRateLimited) { ... }
+//
+// They match both *ErrorResponse, by HTTP status, and *TransportError.
+var (
+	ErrUnauthorized = errors.New("iiko: unauthorized")
+	ErrRateLimited  = errors.New("iiko: rate limited")
+	ErrTimeout      = errors.New("iiko: timeout")
+	ErrServerError  = errors.New("iiko: server error")
+	ErrBadRequest   = errors.New("iiko: bad request")
+)
+
+// Is reports whether the status code of e belongs to the failure class target.
+func (e *ErrorResponse) Is(target error) bool {
+	code := e.StatusCode
+
+	switch target {
+	case ErrUnauthorized:
+		return code == http.StatusUnauthorized || code == http.StatusForbidden
+	case ErrRateLimited:
+		return code == http.StatusTooManyRequests
+	case ErrTimeout:
+		return code == http.StatusRequestTimeout || code == http.StatusGatewayTimeout
+	case ErrServerError:
+		return code >= http.StatusInternalServerError
+	case ErrBadRequest:
+		return code >= http.StatusBadRequest && code < http.StatusInternalServerError &&
+			!e.Is(ErrUnauthorized) && !e.Is(ErrRateLimited) && !e.Is(ErrTimeout)
+	}
+
+	return false
+}
+
+// RetryAfter returns the delay iiko asked for in the Retry-After header, if any.
+func (e *ErrorResponse) RetryAfter() (time.Duration, bool) {
+	if e.Header == nil {
+		return 0, false
+	}
+	return parseRetryAfter(e.Header.Get("Retry-After"))
+}
+
+// TransportError is returned when an API call got no response from iikoCloud:
+// a network failure, a cancelled context, the rate limiter giving up and the like.
+type TransportError struct {
+	// iiko API endpoint of the failed call.
+	Endpoint string
+	// Underlying error.
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("iiko: %s: %v", e.Endpoint, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrTimeout for deadlines and network timeouts.
+func (e *TransportError) Is(target error) bool {
+	if target != ErrTimeout {
+		return false
+	}
+
+	if errors.Is(e.Err, context.DeadlineExceeded) || errors.Is(e.Err, ErrRateLimitWait) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(e.Err, &netErr) && netErr.Timeout()
+}
+
+// wrapTransportError attaches endpoint to errors other than iiko API errors.
+func wrapTransportError(endpoint string, err error) error {
+	var apiErr *ErrorResponse
+	var transportErr *TransportError
+	if errors.As(err, &apiErr) || errors.As(err, &transportErr) {
+		return err
+	}
+	return &TransportError{Endpoint: endpoint, Err: err}
+}
@@ -0,0 +1,168 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestErrorResponseIs(t *testing.T) {
+	classes := []error{ErrUnauthorized, ErrRateLimited, ErrTimeout, ErrServerError, ErrBadRequest}
+
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusNotFound, ErrBadRequest},
+		{http.StatusRequestTimeout, ErrTimeout},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, ErrServerError},
+		{http.StatusBadGateway, ErrServerError},
+		{http.StatusOK, nil},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", &ErrorResponse{StatusCode: tt.status})
+			for _, class := range classes {
+				if got := errors.Is(err, class); got != (class == tt.want) {
+					t.Errorf("errors.Is(%d, %v) = %v", tt.status, class, got)
+				}
+			}
+		})
+	}
+
+	// A gateway timeout is both a timeout and a server error.
+	gatewayTimeout := &ErrorResponse{StatusCode: http.StatusGatewayTimeout}
+	if !errors.Is(gatewayTimeout, ErrTimeout) || !errors.Is(gatewayTimeout, ErrServerError) {
+		t.Error("504 must match ErrTimeout and ErrServerError")
+	}
+}
+
+// timeoutError is a net.Error that timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
+
+func TestTransportErrorIs(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantTimeout bool
+	}{
+		{"deadline", context.DeadlineExceeded, true},
+		{"rate limit wait", ErrRateLimitWait, true},
+		{"network timeout", &net.OpError{Op: "read", Err: timeoutError{}}, true},
+		{"cancelled", context.Canceled, false},
+		{"connection refused", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wrapTransportError("/api/1/organizations", tt.err)
+
+			var transportErr *TransportError
+			if !errors.As(err, &transportErr) || transportErr.Endpoint != "/api/1/organizations" {
+				t.Fatalf("err = %#v, want a TransportError", err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("TransportError does not unwrap to its cause")
+			}
+			if got := errors.Is(err, ErrTimeout); got != tt.wantTimeout {
+				t.Errorf("errors.Is(ErrTimeout) = %v, want %v", got, tt.wantTimeout)
+			}
+			if errors.Is(err, ErrServerError) || errors.Is(err, ErrBadRequest) {
+				t.Error("TransportError matches a status class")
+			}
+		})
+	}
+}
+
+func TestWrapTransportErrorKeepsTypedErrors(t *testing.T) {
+	apiErr := &ErrorResponse{StatusCode: http.StatusBadRequest}
+	transportErr := &TransportError{Endpoint: "/a", Err: context.Canceled}
+
+	for _, err := range []error{apiErr, transportErr, fmt.Errorf("x: %w", apiErr)} {
+		if got := wrapTransportError("/b", err); got != err {
+			t.Errorf("wrapTransportError(%v) = %v, want it unchanged", err, got)
+		}
+	}
+}
+
+func TestErrorResponseError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *ErrorResponse
+		want string
+	}{
+		{"description", &ErrorResponse{StatusCode: 400, ErrorDescription: "Organization not found"}, "Organization not found"},
+		{"no body", &ErrorResponse{StatusCode: 502, Endpoint: "/api/1/organizations"}, "iiko: /api/1/organizations: 502 Bad Gateway"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("%s: Error() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestErrorResponseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{"no header", nil, 0, false},
+		{"seconds", http.Header{"Retry-After": {"3"}}, 3 * time.Second, true},
+		{"missing", http.Header{}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := (&ErrorResponse{Header: tt.header}).RetryAfter()
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: RetryAfter() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantDesc string
+		want     error
+	}{
+		{"iiko json", http.StatusBadRequest, `{"correlationId":"` + testCorrelationID + `","errorDescription":"Bad org","error":"ORG"}`, "Bad org", ErrBadRequest},
+		{"html proxy page", http.StatusBadGateway, `<html>bad gateway</html>`, "", ErrServerError},
+		{"empty rate limit", http.StatusTooManyRequests, ``, "", ErrRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, tt.status, tt.body)
+			})
+			c := newTestClient(t, srv)
+
+			_, err := c.Organizations(context.Background(), &OrganizationsRequest{})
+			var apiErr *ErrorResponse
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an ErrorResponse", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Endpoint != "/api/1/organizations" || string(apiErr.Body) != tt.body {
+				t.Errorf("ErrorResponse = %d %s %q", apiErr.StatusCode, apiErr.Endpoint, apiErr.Body)
+			}
+			if apiErr.ErrorDescription != tt.wantDesc || !errors.Is(err, tt.want) {
+				t.Errorf("description %q, errors.Is(%v) = %v", apiErr.ErrorDescription, tt.want, errors.Is(err, tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package iiko
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Exchange is the raw HTTP exchange of one API call, as iiko support asks for it.
+//
+// The access token is redacted from RequestHeader; bodies are kept exactly as
+// sent and received. Use RedactBody before storing them anywhere shared.
+type Exchange struct {
+	// iiko API endpoint, e.g. "/api/1/deliveries/create".
+	Endpoint string
+	// Full URL of the last attempt.
+	URL string
+	// Headers of the last request, with the access token redacted.
+	RequestHeader http.Header
+	// JSON body sent.
+	RequestBody []byte
+
+	// HTTP status code of the last response. 0 if no response was received.
+	StatusCode int
+	// Headers and raw body of the last response.
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	// Operation ID from the response body, if any.
+	CorrelationID string
+
+	// Number of HTTP attempts made.
+	Attempts int
+	// Time spent in the transport, including retries and backoff.
+	Duration time.Duration
+	// Error returned by the call, nil on success.
+	Err error
+}
+
+type withExchangeCapture struct {
+	exchange *Exchange
+}
+
+func (withExchangeCapture) Apply(*http.Request) {}
+
+// WithExchangeCapture records the raw exchange of one API request into ex.
+// ex is filled in once the call returns, whether it succeeded or not.
+func WithExchangeCapture(ex *Exchange) Option {
+	return withExchangeCapture{exchange: ex}
+}
+
+// WithExchangeRecorder calls fn with the raw exchange of every API call made
+// by the Client once the call returns.
+func WithExchangeRecorder(fn func(ctx context.Context, ex *Exchange)) ClientOption {
+	return func(c *Client) {
+		c.exchangeRecorder = fn
+	}
+}
+
+// captureExchange reports the exchange of call to the per-call captures and
+// the Client's recorder, if any. req is the last request sent, if any.
+func (c *Client) captureExchange(ctx context.Context, call *Call, req *http.Request, err error) {
+	var captures []*Exchange
+	for _, opt := range call.Options {
+		if capture, ok := opt.(withExchangeCapture); ok && capture.exchange != nil {
+			captures = append(captures, capture.exchange)
+		}
+	}
+	if len(captures) == 0 && c.exchangeRecorder == nil {
+		return
+	}
+
+	ex := Exchange{
+		Endpoint:       call.Endpoint,
+		RequestBody:    call.Body,
+		StatusCode:     call.StatusCode,
+		ResponseHeader: call.ResponseHeader,
+		ResponseBody:   call.ResponseBody,
+		CorrelationID:  correlationID(call.ResponseBody),
+		Attempts:       call.Attempts,
+		Duration:       call.Duration,
+		Err:            err,
+	}
+	if req != nil {
+		ex.URL = req.URL.String()
+		ex.RequestHeader = RedactHeader(req.Header)
+	}
+
+	for _, capture := range captures {
+		*capture = ex
+	}
+	if c.exchangeRecorder != nil {
+		c.exchangeRecorder(ctx, &ex)
+	}
+}
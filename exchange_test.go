@@ -0,0 +1,100 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExchangeCapture(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{"success", http.StatusOK, `{"correlationId":"` + testCorrelationID + `"}`, false},
+		{"iiko error", http.StatusBadRequest, `{"correlationId":"` + testCorrelationID + `","errorDescription":"bad"}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Test", "1")
+				writeJSON(w, tt.status, tt.body)
+			})
+
+			var mu sync.Mutex
+			var recorded []*Exchange
+			c := newTestClient(t, srv, WithExchangeRecorder(func(_ context.Context, ex *Exchange) {
+				mu.Lock()
+				defer mu.Unlock()
+				recorded = append(recorded, ex)
+			}))
+
+			var ex Exchange
+			_, err := c.Organizations(context.Background(), &OrganizationsRequest{}, WithExchangeCapture(&ex))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+
+			if ex.Endpoint != "/api/1/organizations" || ex.URL != srv.URL+"/api/1/organizations" {
+				t.Errorf("endpoint %q, URL %q", ex.Endpoint, ex.URL)
+			}
+			if ex.RequestHeader.Get("Authorization") != "Bearer "+Redacted {
+				t.Errorf("Authorization = %q, want it redacted", ex.RequestHeader.Get("Authorization"))
+			}
+			if !strings.Contains(string(ex.RequestBody), "organizationIds") {
+				t.Errorf("request body = %s", ex.RequestBody)
+			}
+			if ex.StatusCode != tt.status || string(ex.ResponseBody) != tt.body || ex.ResponseHeader.Get("X-Test") != "1" {
+				t.Errorf("response %d %s %v", ex.StatusCode, ex.ResponseBody, ex.ResponseHeader)
+			}
+			if ex.CorrelationID != testCorrelationID || ex.Attempts != 1 || ex.Duration <= 0 {
+				t.Errorf("correlation %q, attempts %d, duration %v", ex.CorrelationID, ex.Attempts, ex.Duration)
+			}
+			if !errors.Is(ex.Err, err) {
+				t.Errorf("Err = %v, want %v", ex.Err, err)
+			}
+
+			// The recorder sees the token request and the call.
+			mu.Lock()
+			defer mu.Unlock()
+			if len(recorded) != 2 || recorded[1].Endpoint != "/api/1/organizations" || recorded[1].StatusCode != tt.status {
+				t.Fatalf("recorded %d exchanges", len(recorded))
+			}
+			if recorded[0].Endpoint != "/api/1/access_token" || recorded[0].RequestHeader.Get("Authorization") != "" {
+				t.Errorf("first exchange %s, Authorization %q", recorded[0].Endpoint, recorded[0].RequestHeader.Get("Authorization"))
+			}
+		})
+	}
+}
+
+func TestExchangeCaptureTransportError(t *testing.T) {
+	srv := newTestServer(t, nil)
+	c := newTestClient(t, srv)
+	srv.Close()
+
+	var ex Exchange
+	_, err := c.Organizations(context.Background(), &OrganizationsRequest{}, WithExchangeCapture(&ex))
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("err = %v, want a TransportError", err)
+	}
+	if ex.StatusCode != 0 || ex.ResponseBody != nil || ex.Err == nil || ex.URL == "" {
+		t.Errorf("exchange = %+v", ex)
+	}
+}
+
+func TestExchangeCaptureNil(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, `{}`)
+	})
+	c := newTestClient(t, srv)
+
+	if _, err := c.Organizations(context.Background(), &OrganizationsRequest{}, WithExchangeCapture(nil)); err != nil {
+		t.Fatal(err)
+	}
+}
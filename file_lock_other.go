@@ -0,0 +1,15 @@
+//go:build !unix
+
+package iiko
+
+import "sync"
+
+// fileLockMu stands in for flock where it is not available, so that the
+// lock files of a FileTokenStore only exclude Clients of the same process.
+var fileLockMu sync.Mutex
+
+// lockFile takes fileLockMu; path is ignored.
+func lockFile(string) (unlock func(), err error) {
+	fileLockMu.Lock()
+	return fileLockMu.Unlock, nil
+}
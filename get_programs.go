@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -140,15 +142,11 @@ type Condition struct {
 	CheckSum string `json:"checkSum"`
 }
 
+var getProgramsEndpoint = NewEndpoint[GetProgramsRequest, GetProgramsResponse](EndpointInfo{Path: "/api/1/loyalty/iiko/program", RequiresAuth: true, Idempotent: true})
+
 // GetPrograms returns loyalty programs.
 //
 // iiko API: /api/1/loyalty/iiko/program
-func (c *Client) GetPrograms(req *GetProgramsRequest, opts ...Option) (*GetProgramsResponse, error) {
-	var response GetProgramsResponse
-
-	if err := c.post(true, "/api/1/loyalty/iiko/program", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) GetPrograms(ctx context.Context, req *GetProgramsRequest, opts ...Option) (*GetProgramsResponse, error) {
+	return getProgramsEndpoint.Call(ctx, c, req, opts...)
 }
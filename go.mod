@@ -1,5 +1,9 @@
 module github.com/teztar/iiko-go
 
-go 1.18
+go 1.21
 
-require github.com/google/uuid v1.3.0
+require (
+	github.com/google/uuid v1.3.0
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
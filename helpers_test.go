@@ -0,0 +1,103 @@
+package iiko
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testCorrelationID = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+
+// testServer is an iikoCloud stub: it issues access tokens, unless
+// rejectTokens is set, and passes every other request to handler.
+type testServer struct {
+	*httptest.Server
+
+	tokenRequests atomic.Int32
+	rejectTokens  atomic.Bool
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *testServer {
+	t.Helper()
+
+	s := &testServer{calls: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/1/access_token" {
+			n := s.tokenRequests.Add(1)
+			if s.rejectTokens.Load() {
+				writeJSON(w, http.StatusUnauthorized, `{"errorDescription":"Login test is not authorized"}`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"correlationId":"` + testCorrelationID + `","token":"token-` + strconv.Itoa(int(n)) + `"}`))
+			return
+		}
+
+		s.mu.Lock()
+		s.calls[r.URL.Path]++
+		s.mu.Unlock()
+
+		handler(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// callCount returns how many requests were sent to endpoint.
+func (s *testServer) callCount(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[endpoint]
+}
+
+// newTestClient creates a Client talking to s.
+func newTestClient(t *testing.T, s *testServer, opts ...ClientOption) *Client {
+	t.Helper()
+
+	c, err := NewClient("test", append([]ClientOption{WithBaseURL(s.URL)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+// writeJSON writes body with status.
+func writeJSON(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(body))
+}
+
+// fakeClock is a Clock whose time only moves when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(time.Duration) <-chan time.Time {
+	return make(chan time.Time)
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -2,7 +2,8 @@ package iiko
 
 import "time"
 
-// BaseURL is the base URL of iikoCloud API.
+// BaseURL is the base URL of iikoCloud API in the RU region, used by default.
+// See WithBaseURL and WithRegion to change it.
 const BaseURL string = "https://api-ru.iiko.services"
 
 // DefaultTimeout is the default timeout for iikoCloud API Requests.
@@ -10,8 +11,11 @@ const BaseURL string = "https://api-ru.iiko.services"
 // For each API request a custom timeout can be setted by putting iiko.WithCustomTimeout(time.Duration) option after all args.
 const DefaultTimeout = 15 * time.Second
 
-// IikoTimeLayout is the date-time format using by iiko.
-const IikoTimeLayout = "2006-01-02 15:04:05"
+// IikoTimeLayout is the date-time format using by iiko. See Time.
+const IikoTimeLayout = "2006-01-02 15:04:05.000"
+
+// DefaultTokenLifetime is the lifetime of iikoCloud API Token.
+const DefaultTokenLifetime = time.Hour
 
 // DefaultRefreshTokenInterval is the default timeout for refreshing iikoCloud API Token.
 // For each iiko client a custom timeout can be setted by calling client.SetRefreshTokenTimeout(time.Duration).
@@ -0,0 +1,177 @@
+//go:build ignore
+
+// gen writes mock_gen.go: a Mock method and a programmable Func field for
+// every method of the iiko.API interfaces declared in ../api.go.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+)
+
+type method struct {
+	name    string
+	params  string
+	results string
+	args    string
+	hasReq  bool
+	errOnly bool
+}
+
+func main() {
+	out := flag.String("o", "mock_gen.go", "output file")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "../api.go", nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	interfaces := make(map[string]*ast.InterfaceType)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				interfaces[ts.Name.Name] = it
+			}
+		}
+	}
+
+	api, ok := interfaces["API"]
+	if !ok {
+		log.Fatal("api.go declares no API interface")
+	}
+
+	var methods []method
+	collect(fset, interfaces, api, &methods)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package iikomock\n\n")
+	buf.WriteString("import (\n\t\"context\"\n\n\t\"github.com/teztar/iiko-go\"\n)\n\n")
+	buf.WriteString("// Mock is a programmable iiko.API. Set the Func field of a method to program\n")
+	buf.WriteString("// its response; calling a method whose Func is nil fails with ErrNotProgrammed.\n")
+	buf.WriteString("// Every call is recorded, see Calls.\n")
+	buf.WriteString("type Mock struct {\n\trecorder\n\n")
+	for _, m := range methods {
+		fmt.Fprintf(&buf, "\t%sFunc func(%s) %s\n", m.name, m.params, m.results)
+	}
+	buf.WriteString("}\n\n")
+	buf.WriteString("var _ iiko.API = (*Mock)(nil)\n")
+
+	for _, m := range methods {
+		req := "nil"
+		if m.hasReq {
+			req = "req"
+		}
+		fmt.Fprintf(&buf, "\n// %s implements iiko.API.\n", m.name)
+		fmt.Fprintf(&buf, "func (m *Mock) %s(%s) %s {\n", m.name, m.params, m.results)
+		fmt.Fprintf(&buf, "\tm.record(%q, %s)\n", m.name, req)
+		fmt.Fprintf(&buf, "\tif m.%sFunc == nil {\n", m.name)
+		if m.errOnly {
+			fmt.Fprintf(&buf, "\t\treturn notProgrammed(%q)\n", m.name)
+		} else {
+			fmt.Fprintf(&buf, "\t\treturn nil, notProgrammed(%q)\n", m.name)
+		}
+		buf.WriteString("\t}\n")
+		fmt.Fprintf(&buf, "\treturn m.%sFunc(%s)\n}\n", m.name, m.args)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("%v\n%s", err, buf.Bytes())
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// collect appends the methods of it, expanding embedded interfaces in declaration order.
+func collect(fset *token.FileSet, interfaces map[string]*ast.InterfaceType, it *ast.InterfaceType, methods *[]method) {
+	for _, field := range it.Methods.List {
+		if len(field.Names) == 0 {
+			name := field.Type.(*ast.Ident).Name
+			embedded, ok := interfaces[name]
+			if !ok {
+				log.Fatalf("api.go declares no %s interface", name)
+			}
+			collect(fset, interfaces, embedded, methods)
+			continue
+		}
+
+		fn := field.Type.(*ast.FuncType)
+		m := method{name: field.Names[0].Name}
+
+		var params, args []string
+		for _, p := range fn.Params.List {
+			typ := qualify(fset, p.Type)
+			for _, n := range p.Names {
+				params = append(params, n.Name+" "+typ)
+				if _, variadic := p.Type.(*ast.Ellipsis); variadic {
+					args = append(args, n.Name+"...")
+				} else {
+					args = append(args, n.Name)
+				}
+				if n.Name == "req" {
+					m.hasReq = true
+				}
+			}
+		}
+		m.params = strings.Join(params, ", ")
+		m.args = strings.Join(args, ", ")
+
+		var results []string
+		for _, r := range fn.Results.List {
+			results = append(results, qualify(fset, r.Type))
+		}
+		m.errOnly = len(results) == 1
+		m.results = strings.Join(results, ", ")
+		if len(results) > 1 {
+			m.results = "(" + m.results + ")"
+		}
+
+		*methods = append(*methods, m)
+	}
+}
+
+// qualify prints expr with the exported identifiers of package iiko prefixed by "iiko.".
+func qualify(fset *token.FileSet, expr ast.Expr) string {
+	expr = rewrite(expr)
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		log.Fatal(err)
+	}
+	return buf.String()
+}
+
+func rewrite(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(e.Name) {
+			return &ast.SelectorExpr{X: ast.NewIdent("iiko"), Sel: ast.NewIdent(e.Name)}
+		}
+		return e
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: rewrite(e.X)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: rewrite(e.Elt)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: rewrite(e.Elt)}
+	default:
+		return e
+	}
+}
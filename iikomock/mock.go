@@ -0,0 +1,81 @@
+// Package iikomock provides Mock, a programmable fake of iiko.API for unit
+// tests of code that depends on the iiko client.
+//
+//	m := &iikomock.Mock{}
+//	m.OrganizationsFunc = func(ctx context.Context, req *iiko.OrganizationsRequest, opts ...iiko.Option) (*iiko.OrganizationsResponse, error) {
+//		return &iiko.OrganizationsResponse{Organizations: orgs}, nil
+//	}
+//
+//	svc := NewService(m) // NewService(api iiko.API)
+//	...
+//	calls := m.CallsTo("Organizations")
+//
+// Mock is generated from the interfaces in iiko's api.go; run go generate
+// after adding a method there.
+package iikomock
+
+//go:generate go run gen.go
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotProgrammed is returned by Mock methods whose Func field is nil.
+var ErrNotProgrammed = errors.New("iikomock: method not programmed")
+
+// Call is a recorded call of a Mock method.
+type Call struct {
+	// Method is the name of the called method, e.g. "DeliveryCreate".
+	Method string
+	// Request is the request passed to the method, nil for Menu.
+	Request interface{}
+}
+
+// recorder records the calls of a Mock.
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) record(method string, req interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, Call{Method: method, Request: req})
+}
+
+// Calls returns every call made so far, in order.
+func (r *recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo returns the calls of method made so far, in order.
+func (r *recorder) CallsTo(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var calls []Call
+	for _, call := range r.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the recorded calls. Programmed Func fields are kept.
+func (r *recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
+
+func notProgrammed(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotProgrammed, method)
+}
@@ -0,0 +1,339 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package iikomock
+
+import (
+	"context"
+
+	"github.com/teztar/iiko-go"
+)
+
+// Mock is a programmable iiko.API. Set the Func field of a method to program
+// its response; calling a method whose Func is nil fails with ErrNotProgrammed.
+// Every call is recorded, see Calls.
+type Mock struct {
+	recorder
+
+	OrganizationsFunc             func(ctx context.Context, req *iiko.OrganizationsRequest, opts ...iiko.Option) (*iiko.OrganizationsResponse, error)
+	TerminalGroupsFunc            func(ctx context.Context, req *iiko.TerminalGroupsRequest, opts ...iiko.Option) (*iiko.TerminalGroupsResponse, error)
+	TerminalGroupsIsAliveFunc     func(ctx context.Context, req *iiko.TerminalGroupsIsAliveRequest, opts ...iiko.Option) (*iiko.TerminalGroupsIsAliveResponse, error)
+	NomenclatureFunc              func(ctx context.Context, req *iiko.NomenclatureRequest, opts ...iiko.Option) (*iiko.NomenclatureResponse, error)
+	MenuFunc                      func(ctx context.Context, opts ...iiko.Option) (*iiko.MenuResponse, error)
+	MenuByIdFunc                  func(ctx context.Context, req *iiko.MenuByIdRequest, opts ...iiko.Option) (*iiko.MenuByIdResponse, error)
+	ComboGetCombosInfoFunc        func(ctx context.Context, req *iiko.ComboGetCombosInfoRequest, opts ...iiko.Option) (*iiko.ComboGetCombosInfoResponse, error)
+	StopListsFunc                 func(ctx context.Context, req *iiko.StopListsRequest, opts ...iiko.Option) (*iiko.StopListsResponse, error)
+	PaymentTypesFunc              func(ctx context.Context, req *iiko.PaymentTypesRequest, opts ...iiko.Option) (*iiko.PaymentTypesResponse, error)
+	DeliveriesOrderTypesFunc      func(ctx context.Context, req *iiko.DeliveriesOrderTypesRequest, opts ...iiko.Option) (*iiko.DeliveriesOrderTypesResponse, error)
+	CancelCausesFunc              func(ctx context.Context, req *iiko.CancelCausesRequest, opts ...iiko.Option) (*iiko.CancelCausesResponse, error)
+	DiscountsFunc                 func(ctx context.Context, req *iiko.DiscountsRequest, opts ...iiko.Option) (*iiko.DiscountsResponse, error)
+	TipsTypesFunc                 func(ctx context.Context, req *iiko.TipsTypesRequest, opts ...iiko.Option) (*iiko.TipsTypesResponse, error)
+	RemovalTypesFunc              func(ctx context.Context, req *iiko.RemovalTypesRequest, opts ...iiko.Option) (*iiko.RemovalTypesResponse, error)
+	CitiesFunc                    func(ctx context.Context, req *iiko.CitiesRequest, opts ...iiko.Option) (*iiko.CitiesResponse, error)
+	DeliveryCreateFunc            func(ctx context.Context, req *iiko.DeliveryCreateRequest, opts ...iiko.Option) (*iiko.DeliveryCreateResponse, error)
+	DeliveriesByIDFunc            func(ctx context.Context, req *iiko.DeliveriesByIDRequest, opts ...iiko.Option) (*iiko.DeliveriesByIDResponse, error)
+	UpdateOrderDeliveryStatusFunc func(ctx context.Context, req *iiko.UpdateOrderDeliveryStatusRequest, opts ...iiko.Option) (*iiko.UpdateOrderDeliveryStatusResponse, error)
+	OrderCreateFunc               func(ctx context.Context, req *iiko.OrderCreateRequest, opts ...iiko.Option) (*iiko.OrderCreateResponse, error)
+	CommandsStatusFunc            func(ctx context.Context, req *iiko.CommandsStatusRequest, opts ...iiko.Option) (*iiko.CommandsStatusResponse, error)
+	NotificationsSendFunc         func(ctx context.Context, req *iiko.NotificationsSendRequest, opts ...iiko.Option) (*iiko.NotificationsSendResponse, error)
+	CustomerInfoFunc              func(ctx context.Context, req *iiko.CustomerInfoRequest, opts ...iiko.Option) (*iiko.CustomerInfoResponse, error)
+	CreateOrUpdateFunc            func(ctx context.Context, req *iiko.CreateOrUpdateRequest, opts ...iiko.Option) (*iiko.CreateOrUpdateResponse, error)
+	CardAddFunc                   func(ctx context.Context, req *iiko.CardAddRequest, opts ...iiko.Option) (*iiko.CardAddResponse, error)
+	CustomerCategoriesFunc        func(ctx context.Context, req *iiko.CustomerCategoriesRequest, opts ...iiko.Option) (*iiko.CustomerCategoriesResponse, error)
+	CustomerCategoryAddFunc       func(ctx context.Context, req *iiko.CustomerCategoryAddRequest, opts ...iiko.Option) error
+	CustomerCategoryRemoveFunc    func(ctx context.Context, req *iiko.CustomerCategoryRemoveRequest, opts ...iiko.Option) error
+	DeleteCustomersFunc           func(ctx context.Context, req *iiko.DeleteCustomersRequest, opts ...iiko.Option) (*iiko.DeleteCustomersResponse, error)
+	RestoreCustomersFunc          func(ctx context.Context, req *iiko.RestoreCustomersRequest, opts ...iiko.Option) (*iiko.RestoreCustomersResponse, error)
+	GetProgramsFunc               func(ctx context.Context, req *iiko.GetProgramsRequest, opts ...iiko.Option) (*iiko.GetProgramsResponse, error)
+	WebhookSettingsFunc           func(ctx context.Context, req *iiko.WebhookSettingsRequest, opts ...iiko.Option) (*iiko.WebhookSettingsResponse, error)
+	WebhookUpdateSettingsFunc     func(ctx context.Context, req *iiko.WebhookUpdateSettingsRequest, opts ...iiko.Option) (*iiko.WebhookUpdateSettingsResponse, error)
+}
+
+var _ iiko.API = (*Mock)(nil)
+
+// Organizations implements iiko.API.
+func (m *Mock) Organizations(ctx context.Context, req *iiko.OrganizationsRequest, opts ...iiko.Option) (*iiko.OrganizationsResponse, error) {
+	m.record("Organizations", req)
+	if m.OrganizationsFunc == nil {
+		return nil, notProgrammed("Organizations")
+	}
+	return m.OrganizationsFunc(ctx, req, opts...)
+}
+
+// TerminalGroups implements iiko.API.
+func (m *Mock) TerminalGroups(ctx context.Context, req *iiko.TerminalGroupsRequest, opts ...iiko.Option) (*iiko.TerminalGroupsResponse, error) {
+	m.record("TerminalGroups", req)
+	if m.TerminalGroupsFunc == nil {
+		return nil, notProgrammed("TerminalGroups")
+	}
+	return m.TerminalGroupsFunc(ctx, req, opts...)
+}
+
+// TerminalGroupsIsAlive implements iiko.API.
+func (m *Mock) TerminalGroupsIsAlive(ctx context.Context, req *iiko.TerminalGroupsIsAliveRequest, opts ...iiko.Option) (*iiko.TerminalGroupsIsAliveResponse, error) {
+	m.record("TerminalGroupsIsAlive", req)
+	if m.TerminalGroupsIsAliveFunc == nil {
+		return nil, notProgrammed("TerminalGroupsIsAlive")
+	}
+	return m.TerminalGroupsIsAliveFunc(ctx, req, opts...)
+}
+
+// Nomenclature implements iiko.API.
+func (m *Mock) Nomenclature(ctx context.Context, req *iiko.NomenclatureRequest, opts ...iiko.Option) (*iiko.NomenclatureResponse, error) {
+	m.record("Nomenclature", req)
+	if m.NomenclatureFunc == nil {
+		return nil, notProgrammed("Nomenclature")
+	}
+	return m.NomenclatureFunc(ctx, req, opts...)
+}
+
+// Menu implements iiko.API.
+func (m *Mock) Menu(ctx context.Context, opts ...iiko.Option) (*iiko.MenuResponse, error) {
+	m.record("Menu", nil)
+	if m.MenuFunc == nil {
+		return nil, notProgrammed("Menu")
+	}
+	return m.MenuFunc(ctx, opts...)
+}
+
+// MenuById implements iiko.API.
+func (m *Mock) MenuById(ctx context.Context, req *iiko.MenuByIdRequest, opts ...iiko.Option) (*iiko.MenuByIdResponse, error) {
+	m.record("MenuById", req)
+	if m.MenuByIdFunc == nil {
+		return nil, notProgrammed("MenuById")
+	}
+	return m.MenuByIdFunc(ctx, req, opts...)
+}
+
+// ComboGetCombosInfo implements iiko.API.
+func (m *Mock) ComboGetCombosInfo(ctx context.Context, req *iiko.ComboGetCombosInfoRequest, opts ...iiko.Option) (*iiko.ComboGetCombosInfoResponse, error) {
+	m.record("ComboGetCombosInfo", req)
+	if m.ComboGetCombosInfoFunc == nil {
+		return nil, notProgrammed("ComboGetCombosInfo")
+	}
+	return m.ComboGetCombosInfoFunc(ctx, req, opts...)
+}
+
+// StopLists implements iiko.API.
+func (m *Mock) StopLists(ctx context.Context, req *iiko.StopListsRequest, opts ...iiko.Option) (*iiko.StopListsResponse, error) {
+	m.record("StopLists", req)
+	if m.StopListsFunc == nil {
+		return nil, notProgrammed("StopLists")
+	}
+	return m.StopListsFunc(ctx, req, opts...)
+}
+
+// PaymentTypes implements iiko.API.
+func (m *Mock) PaymentTypes(ctx context.Context, req *iiko.PaymentTypesRequest, opts ...iiko.Option) (*iiko.PaymentTypesResponse, error) {
+	m.record("PaymentTypes", req)
+	if m.PaymentTypesFunc == nil {
+		return nil, notProgrammed("PaymentTypes")
+	}
+	return m.PaymentTypesFunc(ctx, req, opts...)
+}
+
+// DeliveriesOrderTypes implements iiko.API.
+func (m *Mock) DeliveriesOrderTypes(ctx context.Context, req *iiko.DeliveriesOrderTypesRequest, opts ...iiko.Option) (*iiko.DeliveriesOrderTypesResponse, error) {
+	m.record("DeliveriesOrderTypes", req)
+	if m.DeliveriesOrderTypesFunc == nil {
+		return nil, notProgrammed("DeliveriesOrderTypes")
+	}
+	return m.DeliveriesOrderTypesFunc(ctx, req, opts...)
+}
+
+// CancelCauses implements iiko.API.
+func (m *Mock) CancelCauses(ctx context.Context, req *iiko.CancelCausesRequest, opts ...iiko.Option) (*iiko.CancelCausesResponse, error) {
+	m.record("CancelCauses", req)
+	if m.CancelCausesFunc == nil {
+		return nil, notProgrammed("CancelCauses")
+	}
+	return m.CancelCausesFunc(ctx, req, opts...)
+}
+
+// Discounts implements iiko.API.
+func (m *Mock) Discounts(ctx context.Context, req *iiko.DiscountsRequest, opts ...iiko.Option) (*iiko.DiscountsResponse, error) {
+	m.record("Discounts", req)
+	if m.DiscountsFunc == nil {
+		return nil, notProgrammed("Discounts")
+	}
+	return m.DiscountsFunc(ctx, req, opts...)
+}
+
+// TipsTypes implements iiko.API.
+func (m *Mock) TipsTypes(ctx context.Context, req *iiko.TipsTypesRequest, opts ...iiko.Option) (*iiko.TipsTypesResponse, error) {
+	m.record("TipsTypes", req)
+	if m.TipsTypesFunc == nil {
+		return nil, notProgrammed("TipsTypes")
+	}
+	return m.TipsTypesFunc(ctx, req, opts...)
+}
+
+// RemovalTypes implements iiko.API.
+func (m *Mock) RemovalTypes(ctx context.Context, req *iiko.RemovalTypesRequest, opts ...iiko.Option) (*iiko.RemovalTypesResponse, error) {
+	m.record("RemovalTypes", req)
+	if m.RemovalTypesFunc == nil {
+		return nil, notProgrammed("RemovalTypes")
+	}
+	return m.RemovalTypesFunc(ctx, req, opts...)
+}
+
+// Cities implements iiko.API.
+func (m *Mock) Cities(ctx context.Context, req *iiko.CitiesRequest, opts ...iiko.Option) (*iiko.CitiesResponse, error) {
+	m.record("Cities", req)
+	if m.CitiesFunc == nil {
+		return nil, notProgrammed("Cities")
+	}
+	return m.CitiesFunc(ctx, req, opts...)
+}
+
+// DeliveryCreate implements iiko.API.
+func (m *Mock) DeliveryCreate(ctx context.Context, req *iiko.DeliveryCreateRequest, opts ...iiko.Option) (*iiko.DeliveryCreateResponse, error) {
+	m.record("DeliveryCreate", req)
+	if m.DeliveryCreateFunc == nil {
+		return nil, notProgrammed("DeliveryCreate")
+	}
+	return m.DeliveryCreateFunc(ctx, req, opts...)
+}
+
+// DeliveriesByID implements iiko.API.
+func (m *Mock) DeliveriesByID(ctx context.Context, req *iiko.DeliveriesByIDRequest, opts ...iiko.Option) (*iiko.DeliveriesByIDResponse, error) {
+	m.record("DeliveriesByID", req)
+	if m.DeliveriesByIDFunc == nil {
+		return nil, notProgrammed("DeliveriesByID")
+	}
+	return m.DeliveriesByIDFunc(ctx, req, opts...)
+}
+
+// UpdateOrderDeliveryStatus implements iiko.API.
+func (m *Mock) UpdateOrderDeliveryStatus(ctx context.Context, req *iiko.UpdateOrderDeliveryStatusRequest, opts ...iiko.Option) (*iiko.UpdateOrderDeliveryStatusResponse, error) {
+	m.record("UpdateOrderDeliveryStatus", req)
+	if m.UpdateOrderDeliveryStatusFunc == nil {
+		return nil, notProgrammed("UpdateOrderDeliveryStatus")
+	}
+	return m.UpdateOrderDeliveryStatusFunc(ctx, req, opts...)
+}
+
+// OrderCreate implements iiko.API.
+func (m *Mock) OrderCreate(ctx context.Context, req *iiko.OrderCreateRequest, opts ...iiko.Option) (*iiko.OrderCreateResponse, error) {
+	m.record("OrderCreate", req)
+	if m.OrderCreateFunc == nil {
+		return nil, notProgrammed("OrderCreate")
+	}
+	return m.OrderCreateFunc(ctx, req, opts...)
+}
+
+// CommandsStatus implements iiko.API.
+func (m *Mock) CommandsStatus(ctx context.Context, req *iiko.CommandsStatusRequest, opts ...iiko.Option) (*iiko.CommandsStatusResponse, error) {
+	m.record("CommandsStatus", req)
+	if m.CommandsStatusFunc == nil {
+		return nil, notProgrammed("CommandsStatus")
+	}
+	return m.CommandsStatusFunc(ctx, req, opts...)
+}
+
+// NotificationsSend implements iiko.API.
+func (m *Mock) NotificationsSend(ctx context.Context, req *iiko.NotificationsSendRequest, opts ...iiko.Option) (*iiko.NotificationsSendResponse, error) {
+	m.record("NotificationsSend", req)
+	if m.NotificationsSendFunc == nil {
+		return nil, notProgrammed("NotificationsSend")
+	}
+	return m.NotificationsSendFunc(ctx, req, opts...)
+}
+
+// CustomerInfo implements iiko.API.
+func (m *Mock) CustomerInfo(ctx context.Context, req *iiko.CustomerInfoRequest, opts ...iiko.Option) (*iiko.CustomerInfoResponse, error) {
+	m.record("CustomerInfo", req)
+	if m.CustomerInfoFunc == nil {
+		return nil, notProgrammed("CustomerInfo")
+	}
+	return m.CustomerInfoFunc(ctx, req, opts...)
+}
+
+// CreateOrUpdate implements iiko.API.
+func (m *Mock) CreateOrUpdate(ctx context.Context, req *iiko.CreateOrUpdateRequest, opts ...iiko.Option) (*iiko.CreateOrUpdateResponse, error) {
+	m.record("CreateOrUpdate", req)
+	if m.CreateOrUpdateFunc == nil {
+		return nil, notProgrammed("CreateOrUpdate")
+	}
+	return m.CreateOrUpdateFunc(ctx, req, opts...)
+}
+
+// CardAdd implements iiko.API.
+func (m *Mock) CardAdd(ctx context.Context, req *iiko.CardAddRequest, opts ...iiko.Option) (*iiko.CardAddResponse, error) {
+	m.record("CardAdd", req)
+	if m.CardAddFunc == nil {
+		return nil, notProgrammed("CardAdd")
+	}
+	return m.CardAddFunc(ctx, req, opts...)
+}
+
+// CustomerCategories implements iiko.API.
+func (m *Mock) CustomerCategories(ctx context.Context, req *iiko.CustomerCategoriesRequest, opts ...iiko.Option) (*iiko.CustomerCategoriesResponse, error) {
+	m.record("CustomerCategories", req)
+	if m.CustomerCategoriesFunc == nil {
+		return nil, notProgrammed("CustomerCategories")
+	}
+	return m.CustomerCategoriesFunc(ctx, req, opts...)
+}
+
+// CustomerCategoryAdd implements iiko.API.
+func (m *Mock) CustomerCategoryAdd(ctx context.Context, req *iiko.CustomerCategoryAddRequest, opts ...iiko.Option) error {
+	m.record("CustomerCategoryAdd", req)
+	if m.CustomerCategoryAddFunc == nil {
+		return notProgrammed("CustomerCategoryAdd")
+	}
+	return m.CustomerCategoryAddFunc(ctx, req, opts...)
+}
+
+// CustomerCategoryRemove implements iiko.API.
+func (m *Mock) CustomerCategoryRemove(ctx context.Context, req *iiko.CustomerCategoryRemoveRequest, opts ...iiko.Option) error {
+	m.record("CustomerCategoryRemove", req)
+	if m.CustomerCategoryRemoveFunc == nil {
+		return notProgrammed("CustomerCategoryRemove")
+	}
+	return m.CustomerCategoryRemoveFunc(ctx, req, opts...)
+}
+
+// DeleteCustomers implements iiko.API.
+func (m *Mock) DeleteCustomers(ctx context.Context, req *iiko.DeleteCustomersRequest, opts ...iiko.Option) (*iiko.DeleteCustomersResponse, error) {
+	m.record("DeleteCustomers", req)
+	if m.DeleteCustomersFunc == nil {
+		return nil, notProgrammed("DeleteCustomers")
+	}
+	return m.DeleteCustomersFunc(ctx, req, opts...)
+}
+
+// RestoreCustomers implements iiko.API.
+func (m *Mock) RestoreCustomers(ctx context.Context, req *iiko.RestoreCustomersRequest, opts ...iiko.Option) (*iiko.RestoreCustomersResponse, error) {
+	m.record("RestoreCustomers", req)
+	if m.RestoreCustomersFunc == nil {
+		return nil, notProgrammed("RestoreCustomers")
+	}
+	return m.RestoreCustomersFunc(ctx, req, opts...)
+}
+
+// GetPrograms implements iiko.API.
+func (m *Mock) GetPrograms(ctx context.Context, req *iiko.GetProgramsRequest, opts ...iiko.Option) (*iiko.GetProgramsResponse, error) {
+	m.record("GetPrograms", req)
+	if m.GetProgramsFunc == nil {
+		return nil, notProgrammed("GetPrograms")
+	}
+	return m.GetProgramsFunc(ctx, req, opts...)
+}
+
+// WebhookSettings implements iiko.API.
+func (m *Mock) WebhookSettings(ctx context.Context, req *iiko.WebhookSettingsRequest, opts ...iiko.Option) (*iiko.WebhookSettingsResponse, error) {
+	m.record("WebhookSettings", req)
+	if m.WebhookSettingsFunc == nil {
+		return nil, notProgrammed("WebhookSettings")
+	}
+	return m.WebhookSettingsFunc(ctx, req, opts...)
+}
+
+// WebhookUpdateSettings implements iiko.API.
+func (m *Mock) WebhookUpdateSettings(ctx context.Context, req *iiko.WebhookUpdateSettingsRequest, opts ...iiko.Option) (*iiko.WebhookUpdateSettingsResponse, error) {
+	m.record("WebhookUpdateSettings", req)
+	if m.WebhookUpdateSettingsFunc == nil {
+		return nil, notProgrammed("WebhookUpdateSettings")
+	}
+	return m.WebhookUpdateSettingsFunc(ctx, req, opts...)
+}
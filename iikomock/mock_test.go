@@ -0,0 +1,245 @@
+package iikomock_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/teztar/iiko-go"
+	"github.com/teztar/iiko-go/iikomock"
+)
+
+func TestMockProgrammed(t *testing.T) {
+	orgID := uuid.New()
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name    string
+		program func(m *iikomock.Mock)
+		call    func(m *iikomock.Mock) (interface{}, error)
+		want    interface{}
+		wantErr error
+	}{
+		{
+			name: "response",
+			program: func(m *iikomock.Mock) {
+				m.OrganizationsFunc = func(ctx context.Context, req *iiko.OrganizationsRequest, opts ...iiko.Option) (*iiko.OrganizationsResponse, error) {
+					return &iiko.OrganizationsResponse{Organizations: []iiko.Organization{{ID: orgID}}}, nil
+				}
+			},
+			call: func(m *iikomock.Mock) (interface{}, error) {
+				res, err := m.Organizations(context.Background(), &iiko.OrganizationsRequest{})
+				if err != nil {
+					return nil, err
+				}
+				return res.Organizations[0].ID, nil
+			},
+			want: orgID,
+		},
+		{
+			name: "error",
+			program: func(m *iikomock.Mock) {
+				m.DeliveryCreateFunc = func(ctx context.Context, req *iiko.DeliveryCreateRequest, opts ...iiko.Option) (*iiko.DeliveryCreateResponse, error) {
+					return nil, errBoom
+				}
+			},
+			call: func(m *iikomock.Mock) (interface{}, error) {
+				return m.DeliveryCreate(context.Background(), &iiko.DeliveryCreateRequest{})
+			},
+			wantErr: errBoom,
+		},
+		{
+			name: "error only method",
+			program: func(m *iikomock.Mock) {
+				m.CustomerCategoryAddFunc = func(ctx context.Context, req *iiko.CustomerCategoryAddRequest, opts ...iiko.Option) error {
+					return errBoom
+				}
+			},
+			call: func(m *iikomock.Mock) (interface{}, error) {
+				return nil, m.CustomerCategoryAdd(context.Background(), &iiko.CustomerCategoryAddRequest{})
+			},
+			wantErr: errBoom,
+		},
+		{
+			name:    "not programmed",
+			program: func(m *iikomock.Mock) {},
+			call: func(m *iikomock.Mock) (interface{}, error) {
+				return m.Menu(context.Background())
+			},
+			wantErr: iikomock.ErrNotProgrammed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &iikomock.Mock{}
+			tt.program(m)
+
+			got, err := tt.call(m)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want != nil && got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if n := len(m.Calls()); n != 1 {
+				t.Errorf("recorded %d calls, want 1", n)
+			}
+		})
+	}
+}
+
+// TestMockCoversAPI checks that every iiko.API method has a Func field of the
+// same type and fails with ErrNotProgrammed when it is nil, so that
+// mock_gen.go is regenerated after api.go changes.
+func TestMockCoversAPI(t *testing.T) {
+	api := reflect.TypeOf((*iiko.API)(nil)).Elem()
+	m := &iikomock.Mock{}
+	mock := reflect.ValueOf(m)
+
+	for i := 0; i < api.NumMethod(); i++ {
+		method := api.Method(i)
+		t.Run(method.Name, func(t *testing.T) {
+			field, ok := reflect.TypeOf(m).Elem().FieldByName(method.Name + "Func")
+			if !ok {
+				t.Fatalf("Mock has no %sFunc field", method.Name)
+			}
+			if field.Type != method.Type {
+				t.Errorf("%sFunc is %v, want %v", method.Name, field.Type, method.Type)
+			}
+
+			fn := mock.MethodByName(method.Name)
+			args := []reflect.Value{reflect.ValueOf(context.Background())}
+			for j := 1; j < fn.Type().NumIn()-1; j++ {
+				args = append(args, reflect.Zero(fn.Type().In(j)))
+			}
+			out := fn.Call(args)
+			err, _ := out[len(out)-1].Interface().(error)
+			if !errors.Is(err, iikomock.ErrNotProgrammed) {
+				t.Errorf("err = %v, want ErrNotProgrammed", err)
+			}
+
+			calls := m.CallsTo(method.Name)
+			if len(calls) != 1 {
+				t.Fatalf("recorded %d calls to %s, want 1", len(calls), method.Name)
+			}
+		})
+	}
+}
+
+func TestMockCalls(t *testing.T) {
+	m := &iikomock.Mock{}
+	ctx := context.Background()
+	orgs := &iiko.OrganizationsRequest{}
+	status := &iiko.CommandsStatusRequest{CorrelationID: uuid.New()}
+
+	_, _ = m.Organizations(ctx, orgs)
+	_, _ = m.CommandsStatus(ctx, status)
+	_, _ = m.Menu(ctx)
+	_, _ = m.Organizations(ctx, orgs)
+
+	tests := []struct {
+		name string
+		got  []iikomock.Call
+		want []iikomock.Call
+	}{
+		{
+			name: "all",
+			got:  m.Calls(),
+			want: []iikomock.Call{
+				{Method: "Organizations", Request: orgs},
+				{Method: "CommandsStatus", Request: status},
+				{Method: "Menu"},
+				{Method: "Organizations", Request: orgs},
+			},
+		},
+		{
+			name: "to method",
+			got:  m.CallsTo("Organizations"),
+			want: []iikomock.Call{{Method: "Organizations", Request: orgs}, {Method: "Organizations", Request: orgs}},
+		},
+		{
+			name: "to uncalled method",
+			got:  m.CallsTo("DeliveryCreate"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %+v, want %+v", tt.got, tt.want)
+			}
+		})
+	}
+
+	m.MenuFunc = func(ctx context.Context, opts ...iiko.Option) (*iiko.MenuResponse, error) {
+		return &iiko.MenuResponse{}, nil
+	}
+	m.Reset()
+	if calls := m.Calls(); len(calls) != 0 {
+		t.Errorf("Calls() after Reset = %v", calls)
+	}
+	if _, err := m.Menu(ctx); err != nil {
+		t.Errorf("Reset forgot MenuFunc: %v", err)
+	}
+}
+
+func TestMockConcurrentCalls(t *testing.T) {
+	m := &iikomock.Mock{}
+	m.OrganizationsFunc = func(ctx context.Context, req *iiko.OrganizationsRequest, opts ...iiko.Option) (*iiko.OrganizationsResponse, error) {
+		return &iiko.OrganizationsResponse{}, nil
+	}
+
+	const goroutines, calls = 8, 50
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				_, _ = m.Organizations(context.Background(), &iiko.OrganizationsRequest{})
+				_ = m.CallsTo("Organizations")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(m.Calls()); n != goroutines*calls {
+		t.Errorf("recorded %d calls, want %d", n, goroutines*calls)
+	}
+}
+
+// TestMockUpToDate fails when mock_gen.go differs from what go generate
+// would write, e.g. after a method was added to iiko.API.
+func TestMockUpToDate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the generator")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	out := filepath.Join(t.TempDir(), "mock_gen.go")
+	if b, err := exec.Command(goTool, "run", "gen.go", "-o", out).CombinedOutput(); err != nil {
+		t.Fatalf("go run gen.go: %v\n%s", err, b)
+	}
+
+	want, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("mock_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("mock_gen.go is stale; run go generate ./iikomock")
+	}
+}
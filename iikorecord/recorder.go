@@ -0,0 +1,339 @@
+// Package iikorecord records iikoCloud API exchanges into cassette files and
+// replays them offline, for deterministic tests of code built on iiko.Client.
+//
+// Access tokens, credentials and customer personal data are scrubbed with
+// iiko.RedactBody and iiko.RedactHeader before anything is written to disk.
+// Requests are matched on endpoint plus normalized body; identical requests
+// are replayed in the order they were recorded, e.g. /commands/status polling
+// that returns InProgress and then Success. Values the code under test
+// generates anew on every run, like order IDs or timestamps, are left out of
+// matching with WithIgnoredFields or WithBodyNormalizer.
+//
+//	rec, err := iikorecord.New("testdata/create_order.json", iikorecord.ModeAuto, nil)
+//	...
+//	defer rec.Save()
+//
+//	client, err := iiko.NewClient(apiLogin, iiko.WithHTTPClient(rec.HTTPClient()))
+package iikorecord
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/teztar/iiko-go"
+)
+
+// ErrNoInteraction is returned in replay mode for requests the cassette has no
+// recorded exchange for.
+var ErrNoInteraction = errors.New("iikorecord: no recorded interaction")
+
+// Mode tells a Recorder whether to talk to iikoCloud.
+type Mode int
+
+const (
+	// ModeReplay serves every request from the cassette and never uses the network.
+	ModeReplay Mode = iota
+	// ModeRecord sends every request to iikoCloud and records the exchange.
+	ModeRecord
+	// ModeAuto replays if the cassette file exists and records otherwise.
+	ModeAuto
+)
+
+// Cassette is the content of a cassette file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded exchange.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a scrubbed request of an Interaction.
+type RecordedRequest struct {
+	Method   string      `json:"method"`
+	Endpoint string      `json:"endpoint"`
+	Header   http.Header `json:"header,omitempty"`
+	Body     string      `json:"body"`
+}
+
+// RecordedResponse is a scrubbed response of an Interaction.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that records or replays iikoCloud exchanges.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	// normalizers are applied to request bodies before matching.
+	normalizers []BodyNormalizer
+
+	mu       sync.Mutex
+	cassette Cassette
+	// replayed counts interactions served per match key.
+	replayed map[string]int
+}
+
+// BodyNormalizer rewrites the scrubbed JSON body of a request to endpoint
+// before it is matched against recorded requests.
+type BodyNormalizer func(endpoint string, body []byte) []byte
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithBodyNormalizer makes the Recorder match requests on their bodies as
+// rewritten by fn. Both the recorded and the replayed request are rewritten,
+// so the cassette keeps the original body.
+func WithBodyNormalizer(fn BodyNormalizer) Option {
+	return func(r *Recorder) {
+		r.normalizers = append(r.normalizers, fn)
+	}
+}
+
+// WithIgnoredFields makes the Recorder match requests regardless of the values
+// of the given JSON object keys, at any depth, e.g. "id" of an order created
+// with a fresh UUID or "createdAt".
+func WithIgnoredFields(keys ...string) Option {
+	ignored := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		ignored[key] = true
+	}
+
+	return WithBodyNormalizer(func(_ string, body []byte) []byte {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+
+		var v interface{}
+		if dec.Decode(&v) != nil {
+			return body
+		}
+		normalized, err := json.Marshal(ignoreFields(v, ignored))
+		if err != nil {
+			return body
+		}
+		return normalized
+	})
+}
+
+// ignoredValue replaces the values of ignored fields.
+const ignoredValue = "*"
+
+func ignoreFields(v interface{}, ignored map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if ignored[k] {
+				v[k] = ignoredValue
+			} else {
+				v[k] = ignoreFields(child, ignored)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = ignoreFields(child, ignored)
+		}
+	}
+	return v
+}
+
+// New creates a Recorder for the cassette at path. In record mode requests go
+// through transport, or http.DefaultTransport if it is nil. In replay mode the
+// cassette must exist.
+func New(path string, mode Mode, transport http.RoundTripper, opts ...Option) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+		replayed:  make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode == ModeAuto {
+		r.mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			r.mode = ModeReplay
+		}
+	}
+
+	if r.mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("iikorecord: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("iikorecord: cannot parse cassette %s: %w", path, err)
+		}
+	}
+
+	return r, nil
+}
+
+// Mode returns the mode the Recorder runs in; ModeAuto is resolved to
+// ModeReplay or ModeRecord.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// HTTPClient returns an http.Client that sends requests through r.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := req.URL.Path
+	recorded := RecordedRequest{
+		Method:   req.Method,
+		Endpoint: endpoint,
+		Header:   iiko.RedactHeader(req.Header),
+		Body:     string(iiko.RedactBody(endpoint, body)),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+	return r.record(req, recorded)
+}
+
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	key := r.matchKey(recorded)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Serve identical requests in recording order and keep serving the last
+	// one once they run out.
+	var candidates []int
+	for i, in := range r.cassette.Interactions {
+		if r.matchKey(in.Request) == key {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, recorded.Endpoint)
+	}
+
+	n := r.replayed[key]
+	r.replayed[key] = n + 1
+	if n >= len(candidates) {
+		n = len(candidates) - 1
+	}
+
+	return newResponse(req, r.cassette.Interactions[candidates[n]].Response), nil
+}
+
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// The body is scrubbed, so its recorded length would be wrong.
+	header := resp.Header.Clone()
+	header.Del("Content-Length")
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       string(iiko.RedactBody(recorded.Endpoint, body)),
+		},
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// Save writes the recorded cassette to its file. It does nothing in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("iikorecord: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0o644); err != nil {
+		return fmt.Errorf("iikorecord: %w", err)
+	}
+	return nil
+}
+
+// matchKey identifies requests that are replayed by the same interactions.
+// Bodies are already in the canonical form of iiko.RedactBody; the
+// normalizers of r are applied on top.
+func (r *Recorder) matchKey(req RecordedRequest) string {
+	body := []byte(req.Body)
+	for _, normalize := range r.normalizers {
+		body = normalize(req.Endpoint, body)
+	}
+	return req.Method + " " + req.Endpoint + "\n" + string(body)
+}
+
+// readBody returns the request body and restores it for the real transport.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func newResponse(req *http.Request, recorded RecordedResponse) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+}
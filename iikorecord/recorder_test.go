@@ -0,0 +1,288 @@
+package iikorecord_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teztar/iiko-go"
+	"github.com/teztar/iiko-go/iikorecord"
+	"github.com/teztar/iiko-go/iikotest"
+)
+
+type createRequest struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt string    `json:"createdAt"`
+	Phone     string    `json:"phone"`
+	Amount    int       `json:"amount"`
+}
+
+type createResponse struct {
+	Status string `json:"status"`
+}
+
+var createEndpoint = iiko.NewEndpoint[createRequest, createResponse](iiko.EndpointInfo{
+	Path:         "/api/1/test/record_create",
+	RequiresAuth: true,
+})
+
+// newIikoServer answers token requests and calls to createEndpoint with the
+// statuses, one per call, keeping the last one.
+func newIikoServer(t *testing.T, statuses ...string) *httptest.Server {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/1/access_token" {
+			_, _ = io.WriteString(w, `{"correlationId":"3fa85f64-5717-4562-b3fc-2c963f66afa6","token":"secret-token"}`)
+			return
+		}
+		n := int(calls.Add(1)) - 1
+		_ = json.NewEncoder(w).Encode(createResponse{Status: statuses[min(n, len(statuses)-1)]})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newClient(t *testing.T, rec *iikorecord.Recorder, baseURL string) *iiko.Client {
+	t.Helper()
+
+	c, err := iiko.NewClient("api-login", iiko.WithBaseURL(baseURL), iiko.WithHTTPClient(rec.HTTPClient()))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+// record makes a cassette at path with one call per request.
+func record(t *testing.T, path string, statuses []string, reqs ...createRequest) {
+	t.Helper()
+
+	srv := newIikoServer(t, statuses...)
+	rec, err := iikorecord.New(path, iikorecord.ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newClient(t, rec, srv.URL)
+	for _, req := range reqs {
+		if _, err = createEndpoint.Call(context.Background(), c, &req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newCreateRequest() createRequest {
+	return createRequest{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().Format(iiko.IikoTimeLayout),
+		Phone:     "+79990000000",
+		Amount:    2,
+	}
+}
+
+func TestRecordScrubsSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	record(t, path, []string{"Success"}, newCreateRequest())
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret-token", "api-login", "+79990000000"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+}
+
+func TestReplayMatching(t *testing.T) {
+	recorded := newCreateRequest()
+
+	changed := newCreateRequest()
+	otherAmount := changed
+	otherAmount.Amount = 3
+
+	tests := []struct {
+		name    string
+		opts    []iikorecord.Option
+		req     createRequest
+		wantErr bool
+	}{
+		{"same request", nil, recorded, false},
+		{"fresh id and time", nil, changed, true},
+		{"ignored fields", []iikorecord.Option{iikorecord.WithIgnoredFields("id", "createdAt")}, changed, false},
+		{"ignored fields keep the rest", []iikorecord.Option{iikorecord.WithIgnoredFields("id", "createdAt")}, otherAmount, true},
+		{"normalizer", []iikorecord.Option{iikorecord.WithBodyNormalizer(func(endpoint string, body []byte) []byte {
+			if endpoint != createEndpoint.Info().Path {
+				return body
+			}
+			var req createRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				return body
+			}
+			req.ID, req.CreatedAt = uuid.Nil, ""
+			normalized, _ := json.Marshal(req)
+			return normalized
+		})}, changed, false},
+	}
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	record(t, path, []string{"Success"}, recorded)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := iikorecord.New(path, iikorecord.ModeReplay, nil, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Replay never reaches this URL.
+			c := newClient(t, rec, "http://iiko.invalid")
+
+			res, err := createEndpoint.Call(context.Background(), c, &tt.req)
+			if tt.wantErr {
+				if !errors.Is(err, iikorecord.ErrNoInteraction) {
+					t.Fatalf("err = %v, want ErrNoInteraction", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != "Success" {
+				t.Errorf("Status = %q", res.Status)
+			}
+		})
+	}
+}
+
+func TestReplayOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	req := newCreateRequest()
+	record(t, path, []string{"InProgress", "Success"}, req, req)
+
+	rec, err := iikorecord.New(path, iikorecord.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newClient(t, rec, "http://iiko.invalid")
+
+	for i, want := range []string{"InProgress", "Success", "Success"} {
+		res, err := createEndpoint.Call(context.Background(), c, &req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != want {
+			t.Errorf("call %d: Status = %q, want %q", i, res.Status, want)
+		}
+	}
+}
+
+func TestModeAuto(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.json")
+	if err := os.WriteFile(existing, []byte(`{"interactions":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		mode    iikorecord.Mode
+		want    iikorecord.Mode
+		wantErr bool
+	}{
+		{"auto with cassette", existing, iikorecord.ModeAuto, iikorecord.ModeReplay, false},
+		{"auto without cassette", filepath.Join(dir, "new.json"), iikorecord.ModeAuto, iikorecord.ModeRecord, false},
+		{"replay without cassette", filepath.Join(dir, "missing.json"), iikorecord.ModeReplay, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := iikorecord.New(tt.path, tt.mode, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if err == nil && rec.Mode() != tt.want {
+				t.Errorf("Mode() = %v, want %v", rec.Mode(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordReplayCustomerInfo(t *testing.T) {
+	srv := iikotest.NewServer()
+	t.Cleanup(srv.Close)
+
+	phone, name := "+79990000000", "Ivan"
+	birthday := iiko.Time{Time: time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)}
+	id := srv.AddCustomer(iiko.CustomerInfoResponse{
+		Phone:          &phone,
+		Name:           &name,
+		Birthday:       &birthday,
+		Cards:          []iiko.Card{{Id: "card", Track: "track-1", Number: "1234567890", ValidToDate: iiko.Time{Time: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}}},
+		WalletBalances: []iiko.WalletBalance{{Id: "wallet", Balance: iiko.NewMoney(15050, 2)}},
+	})
+	req := &iiko.CustomerInfoRequest{Phone: &phone, Type: iiko.CustomerInfoTypePhone, OrganizationId: uuid.NewString()}
+
+	path := filepath.Join(t.TempDir(), "customer_info.json")
+	rec, err := iikorecord.New(path, iikorecord.ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := iiko.NewClient(iikotest.APILogin, iiko.WithBaseURL(srv.URL), iiko.WithHTTPClient(rec.HTTPClient()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	if _, err = c.CustomerInfo(context.Background(), req); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err = rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err = iikorecord.New(path, iikorecord.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := newClient(t, rec, "http://iiko.invalid").CustomerInfo(context.Background(), req)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+
+	redactedBirthday, _ := iiko.ParseTime(iiko.RedactedTime)
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"id", replayed.Id, id},
+		{"birthday", replayed.Birthday.Time, redactedBirthday.Time},
+		{"phone", *replayed.Phone, iiko.Redacted},
+		{"name", *replayed.Name, iiko.Redacted},
+		{"card number", replayed.Cards[0].Number, iiko.Redacted},
+		{"card track", replayed.Cards[0].Track, iiko.Redacted},
+		{"card validity", replayed.Cards[0].ValidToDate.Year(), 2030},
+		{"balance", replayed.WalletBalances[0].Balance, iiko.NewMoney(15050, 2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,408 @@
+// Package iikotest provides an in-memory fake of iikoCloud for integration
+// tests of code built on iiko.Client.
+//
+// The fake serves the access token, organizations, terminal groups,
+// nomenclature, menu, stop lists, deliveries create/by_id/status, commands
+// status, webhook settings and loyalty customer endpoints from a mutable state that tests seed
+// and inspect through Server methods. Deliveries are created asynchronously
+// (InProgress, then Success or Error), tokens expire with 401 responses, and
+// order changes that pass the webhook filter are pushed as webhooks to a
+// configured URL.
+//
+//	srv := iikotest.NewServer(iikotest.WithCreationDelay(0))
+//	defer srv.Close()
+//	srv.AddOrganization(iiko.Organization{ID: orgID, Name: "Pizza"})
+//
+//	client, err := iiko.NewClient(iikotest.APILogin, iiko.WithBaseURL(srv.URL))
+package iikotest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teztar/iiko-go"
+)
+
+// APILogin is the apiLogin the fake accepts unless WithAPILogin sets another.
+const APILogin = "iikotest"
+
+// Option customizes a Server at construction time.
+type Option func(*Server)
+
+// WithAPILogin makes the fake accept only apiLogin for /api/1/access_token.
+func WithAPILogin(apiLogin string) Option {
+	return func(s *Server) {
+		s.apiLogin = apiLogin
+	}
+}
+
+// WithTokenTTL sets how long issued access tokens are valid. Default is iiko.DefaultTokenLifetime.
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.tokenTTL = ttl
+	}
+}
+
+// WithCreationDelay sets how long created deliveries stay InProgress. Default
+// is one second. With a negative delay deliveries stay InProgress until Settle
+// is called.
+func WithCreationDelay(d time.Duration) Option {
+	return func(s *Server) {
+		s.creationDelay = d
+	}
+}
+
+// WithWebhook makes the fake push webhook events to url, with authToken in the
+// Authorization header as iikoCloud does. /webhooks/update_settings changes them too.
+func WithWebhook(url, authToken string) Option {
+	return func(s *Server) {
+		s.webhookURL = url
+		s.webhookAuthToken = authToken
+		s.webhookFilter = iiko.WebHooksFilter{DeliveryOrderFilter: &iiko.DeliveryOrderFilter{Errors: true}}
+	}
+}
+
+// OrderValidator decides whether a delivery is created. Returning nil creates
+// it; returning an ErrorInfo makes its creation fail with CreationStatus Error.
+type OrderValidator func(req *iiko.DeliveryCreateRequest) *iiko.ErrorInfo
+
+// Request is a request received by the fake.
+type Request struct {
+	Endpoint string
+	Body     []byte
+}
+
+// Server is a fake iikoCloud API server.
+type Server struct {
+	*httptest.Server
+
+	apiLogin      string
+	tokenTTL      time.Duration
+	creationDelay time.Duration
+	// afterFunc starts creation timers; tests replace time.AfterFunc to fire
+	// them by hand.
+	afterFunc func(d time.Duration, f func()) *time.Timer
+
+	mu               sync.Mutex
+	webhookURL       string
+	webhookAuthToken string
+	webhookFilter    iiko.WebHooksFilter
+	tokens           map[string]time.Time
+	requests         []Request
+	organizations    []iiko.Organization
+	terminalGroups   []terminalGroup
+	nomenclature     map[uuid.UUID]iiko.NomenclatureResponse
+	menu             iiko.MenuResponse
+	menus            map[string]iiko.MenuByIdResponse
+	stopLists        map[uuid.UUID]iiko.TerminalGroupStopList
+	orders           map[uuid.UUID]*iiko.DeliveryOrderInfo
+	orderIDs         []uuid.UUID
+	commands         map[uuid.UUID]*iiko.CommandsStatusResponse
+	pending          map[uuid.UUID]pendingOrder
+	timers           map[uuid.UUID]*time.Timer
+	validator        OrderValidator
+	customers        map[string]*iiko.CustomerInfoResponse
+	customerIDs      []string
+	categories       []iiko.Category
+	webhooks         []iiko.WebhookEvent
+	nextOrderNumber  int
+	closed           bool
+
+	webhookWG sync.WaitGroup
+}
+
+type terminalGroup struct {
+	item  iiko.TerminalGroupItem
+	alive bool
+}
+
+type pendingOrder struct {
+	correlationID uuid.UUID
+	errorInfo     *iiko.ErrorInfo
+}
+
+// NewServer starts a fake iikoCloud server. Point a Client at it with
+// iiko.WithBaseURL(srv.URL).
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		apiLogin:      APILogin,
+		tokenTTL:      iiko.DefaultTokenLifetime,
+		creationDelay: time.Second,
+		afterFunc:     time.AfterFunc,
+		tokens:        make(map[string]time.Time),
+		nomenclature:  make(map[uuid.UUID]iiko.NomenclatureResponse),
+		menus:         make(map[string]iiko.MenuByIdResponse),
+		stopLists:     make(map[uuid.UUID]iiko.TerminalGroupStopList),
+		orders:        make(map[uuid.UUID]*iiko.DeliveryOrderInfo),
+		commands:      make(map[uuid.UUID]*iiko.CommandsStatusResponse),
+		pending:       make(map[uuid.UUID]pendingOrder),
+		timers:        make(map[uuid.UUID]*time.Timer),
+		customers:     make(map[string]*iiko.CustomerInfoResponse),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close shuts the server down and waits for webhooks in flight. Deliveries
+// still InProgress stay so, and no webhooks are sent after Close.
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	for id, timer := range s.timers {
+		timer.Stop()
+		delete(s.timers, id)
+	}
+	s.mu.Unlock()
+
+	s.Server.Close()
+	s.webhookWG.Wait()
+}
+
+// ExpireTokens invalidates every issued access token, so that the next
+// authorized call gets a 401.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens = make(map[string]time.Time)
+}
+
+// Requests returns the requests received so far, token requests included.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// Webhooks returns the webhook events raised so far, including those not sent
+// because no webhook URL is set or the webhook filter excludes them.
+func (s *Server) Webhooks() []iiko.WebhookEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]iiko.WebhookEvent(nil), s.webhooks...)
+}
+
+type handlerFunc func(s *Server, body []byte) (interface{}, *iiko.ErrorResponse)
+
+var handlers = map[string]handlerFunc{
+	"/api/1/organizations":                           (*Server).handleOrganizations,
+	"/api/1/terminal_groups":                         (*Server).handleTerminalGroups,
+	"/api/1/terminal_groups/is_alive":                (*Server).handleTerminalGroupsIsAlive,
+	"/api/1/nomenclature":                            (*Server).handleNomenclature,
+	"/api/2/menu":                                    (*Server).handleMenu,
+	"/api/2/menu/by_id":                              (*Server).handleMenuByID,
+	"/api/1/stop_lists":                              (*Server).handleStopLists,
+	"/api/1/deliveries/create":                       (*Server).handleDeliveryCreate,
+	"/api/1/deliveries/by_id":                        (*Server).handleDeliveriesByID,
+	"/api/1/deliveries/update_order_delivery_status": (*Server).handleUpdateOrderDeliveryStatus,
+	"/api/1/commands/status":                         (*Server).handleCommandsStatus,
+	"/api/1/webhooks/settings":                       (*Server).handleWebhookSettings,
+	"/api/1/webhooks/update_settings":                (*Server).handleWebhookUpdateSettings,
+	"/api/1/loyalty/iiko/customer/info":              (*Server).handleCustomerInfo,
+	"/api/1/loyalty/iiko/customer/create_or_update":  (*Server).handleCreateOrUpdate,
+	"/api/1/loyalty/iiko/customer/card/add":          (*Server).handleCardAdd,
+	"/api/1/loyalty/iiko/customer_category":          (*Server).handleCustomerCategories,
+	"/api/1/loyalty/iiko/customer_category/add":      (*Server).handleCustomerCategoryAdd,
+	"/api/1/loyalty/iiko/customer_category/remove":   (*Server).handleCustomerCategoryRemove,
+	"/api/1/loyalty/iiko/delete_customers":           (*Server).handleDeleteCustomers,
+	"/api/1/loyalty/iiko/restore_customers":          (*Server).handleRestoreCustomers,
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(r.Body); err != nil {
+		writeError(w, badRequest(err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, Request{Endpoint: r.URL.Path, Body: body.Bytes()})
+
+	if r.URL.Path == "/api/1/access_token" {
+		s.handleAccessToken(w, body.Bytes())
+		return
+	}
+
+	handle, ok := handlers[r.URL.Path]
+	if !ok {
+		writeError(w, &iiko.ErrorResponse{
+			StatusCode:       http.StatusNotFound,
+			CorrelationID:    uuid.New(),
+			ErrorDescription: "iikotest: endpoint is not implemented: " + r.URL.Path,
+			ErrorField:       "NotFound",
+		})
+		return
+	}
+
+	if !s.authorized(r) {
+		writeError(w, &iiko.ErrorResponse{
+			StatusCode:       http.StatusUnauthorized,
+			CorrelationID:    uuid.New(),
+			ErrorDescription: "Token is expired or invalid",
+			ErrorField:       "Unauthorized",
+		})
+		return
+	}
+
+	response, errResp := handle(s, body.Bytes())
+	if errResp != nil {
+		writeError(w, errResp)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) handleAccessToken(w http.ResponseWriter, body []byte) {
+	var req iiko.AccessTokenRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, badRequest(err.Error()))
+		return
+	}
+	if req.ApiLogin != s.apiLogin {
+		writeError(w, &iiko.ErrorResponse{
+			StatusCode:       http.StatusUnauthorized,
+			CorrelationID:    uuid.New(),
+			ErrorDescription: "Login " + req.ApiLogin + " is not authorized",
+			ErrorField:       "Unauthorized",
+		})
+		return
+	}
+
+	token := uuid.NewString()
+	s.tokens[token] = time.Now().Add(s.tokenTTL)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(iiko.AccessTokenResponse{
+		CorrelationID: uuid.New(),
+		Token:         token,
+	})
+}
+
+// authorized reports whether r carries a valid access token. s.mu must be held.
+func (s *Server) authorized(r *http.Request) bool {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if len(auth) <= len(prefix) || auth[:len(prefix)] != prefix {
+		return false
+	}
+	expiresAt, ok := s.tokens[auth[len(prefix):]]
+	return ok && time.Now().Before(expiresAt)
+}
+
+// pushWebhook sends event to the configured webhook URL in the background if
+// the webhook filter lets it through and the server is not closed.
+// s.mu must be held.
+func (s *Server) pushWebhook(eventType iiko.WebhookEventType, organizationID uuid.UUID, info interface{}) {
+	eventInfo, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+
+	event := iiko.WebhookEvent{
+		EventType:      eventType,
+		EventTime:      iiko.Time{Time: time.Now()},
+		OrganizationID: organizationID,
+		CorrelationID:  uuid.New(),
+		EventInfo:      eventInfo,
+	}
+	s.webhooks = append(s.webhooks, event)
+
+	if s.closed || s.webhookURL == "" || !s.webhookWanted(eventType, info) {
+		return
+	}
+
+	body, err := json.Marshal([]iiko.WebhookEvent{event})
+	if err != nil {
+		return
+	}
+
+	url, authToken := s.webhookURL, s.webhookAuthToken
+	s.webhookWG.Add(1)
+	go func() {
+		defer s.webhookWG.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authToken)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return
+		}
+		resp.Body.Close()
+	}()
+}
+
+// webhookWanted reports whether the webhook filter lets an event through. Like
+// iikoCloud, delivery order errors need DeliveryOrderFilter.Errors; updates
+// need a DeliveryOrderFilter whose OrderStatuses, if any, list the order status.
+// s.mu must be held.
+func (s *Server) webhookWanted(eventType iiko.WebhookEventType, info interface{}) bool {
+	filter := s.webhookFilter.DeliveryOrderFilter
+	if filter == nil {
+		return false
+	}
+
+	switch eventType {
+	case iiko.DeliveryOrderErrorWebhookEvent:
+		return filter.Errors
+	case iiko.DeliveryOrderUpdateWebhookEvent:
+		order, ok := info.(*iiko.DeliveryOrderInfo)
+		if !ok || len(filter.OrderStatuses) == 0 {
+			return true
+		}
+		for _, status := range filter.OrderStatuses {
+			if status == string(order.Order.Status) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, e *iiko.ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(e.StatusCode)
+	_ = json.NewEncoder(w).Encode(e)
+}
+
+func badRequest(description string) *iiko.ErrorResponse {
+	return &iiko.ErrorResponse{
+		StatusCode:       http.StatusBadRequest,
+		CorrelationID:    uuid.New(),
+		ErrorDescription: description,
+		ErrorField:       "BadRequest",
+	}
+}
+
+// decode unmarshals a request body, reporting errors as iiko does.
+func decode(body []byte, v interface{}) *iiko.ErrorResponse {
+	if err := json.Unmarshal(body, v); err != nil {
+		return badRequest(err.Error())
+	}
+	return nil
+}
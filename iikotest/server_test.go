@@ -0,0 +1,230 @@
+package iikotest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teztar/iiko-go"
+)
+
+// webhookReceiver collects the events pushed to it.
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	events []iiko.WebhookEvent
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	t.Helper()
+
+	r := &webhookReceiver{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var events []iiko.WebhookEvent
+		if err := json.NewDecoder(req.Body).Decode(&events); err != nil {
+			t.Errorf("decode webhook: %v", err)
+		}
+		r.mu.Lock()
+		r.events = append(r.events, events...)
+		r.mu.Unlock()
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *webhookReceiver) eventTypes() []iiko.WebhookEventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	types := make([]iiko.WebhookEventType, 0, len(r.events))
+	for _, e := range r.events {
+		types = append(types, e.EventType)
+	}
+	return types
+}
+
+func newClient(t *testing.T, s *Server) *iiko.Client {
+	t.Helper()
+
+	c, err := iiko.NewClient(APILogin, iiko.WithBaseURL(s.URL))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func createDelivery(t *testing.T, c *iiko.Client) uuid.UUID {
+	t.Helper()
+
+	res, err := c.DeliveryCreate(context.Background(), &iiko.DeliveryCreateRequest{
+		OrganizationId:  uuid.New(),
+		TerminalGroupId: uuid.New(),
+	})
+	if err != nil {
+		t.Fatalf("DeliveryCreate: %v", err)
+	}
+	return res.OrderInfo.ID
+}
+
+func TestWebhookFilter(t *testing.T) {
+	failing := func(*iiko.DeliveryCreateRequest) *iiko.ErrorInfo {
+		return &iiko.ErrorInfo{Code: "Failed", Description: "failed"}
+	}
+
+	tests := []struct {
+		name      string
+		filter    iiko.WebHooksFilter
+		validator OrderValidator
+		status    iiko.DeliveryStatus
+		want      []iiko.WebhookEventType
+	}{
+		{
+			name:   "no delivery filter",
+			status: iiko.DeliveryStatusOnWay,
+		},
+		{
+			name:   "all statuses",
+			filter: iiko.WebHooksFilter{DeliveryOrderFilter: &iiko.DeliveryOrderFilter{}},
+			status: iiko.DeliveryStatusOnWay,
+			want:   []iiko.WebhookEventType{iiko.DeliveryOrderUpdateWebhookEvent, iiko.DeliveryOrderUpdateWebhookEvent},
+		},
+		{
+			name:   "listed status",
+			filter: iiko.WebHooksFilter{DeliveryOrderFilter: &iiko.DeliveryOrderFilter{OrderStatuses: []string{"OnWay"}}},
+			status: iiko.DeliveryStatusOnWay,
+			want:   []iiko.WebhookEventType{iiko.DeliveryOrderUpdateWebhookEvent},
+		},
+		{
+			name:   "unlisted status",
+			filter: iiko.WebHooksFilter{DeliveryOrderFilter: &iiko.DeliveryOrderFilter{OrderStatuses: []string{"Delivered"}}},
+			status: iiko.DeliveryStatusOnWay,
+		},
+		{
+			name:      "errors",
+			filter:    iiko.WebHooksFilter{DeliveryOrderFilter: &iiko.DeliveryOrderFilter{Errors: true, OrderStatuses: []string{"Delivered"}}},
+			validator: failing,
+			status:    iiko.DeliveryStatusOnWay,
+			want:      []iiko.WebhookEventType{iiko.DeliveryOrderErrorWebhookEvent},
+		},
+		{
+			name:      "errors off",
+			filter:    iiko.WebHooksFilter{DeliveryOrderFilter: &iiko.DeliveryOrderFilter{OrderStatuses: []string{"Delivered"}}},
+			validator: failing,
+			status:    iiko.DeliveryStatusOnWay,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newWebhookReceiver(t)
+			s := NewServer(WithCreationDelay(-1))
+			s.SetOrderValidator(tt.validator)
+			c := newClient(t, s)
+
+			_, err := c.WebhookUpdateSettings(context.Background(), &iiko.WebhookUpdateSettingsRequest{
+				WebHooksUri:    receiver.URL,
+				WebHooksFilter: tt.filter,
+			})
+			if err != nil {
+				t.Fatalf("WebhookUpdateSettings: %v", err)
+			}
+
+			id := createDelivery(t, c)
+			s.Settle()
+			s.SetDeliveryStatus(id, tt.status)
+			// Close waits for the webhooks in flight.
+			s.Close()
+
+			got := receiver.eventTypes()
+			if len(got) != len(tt.want) {
+				t.Fatalf("sent %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("sent %v, want %v", got, tt.want)
+				}
+			}
+			if n := len(s.Webhooks()); n != 2 {
+				t.Errorf("Webhooks() has %d events, want 2", n)
+			}
+		})
+	}
+}
+
+func TestCloseStopsCreationTimers(t *testing.T) {
+	tests := []struct {
+		name string
+		// fire runs the timer callback after Close, as a timer that fired
+		// while Close held the lock would.
+		fire bool
+	}{
+		{"pending timer", false},
+		{"timer firing during close", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := newWebhookReceiver(t)
+			s := NewServer(WithWebhook(receiver.URL, "secret"))
+			var fire func()
+			s.afterFunc = func(_ time.Duration, f func()) *time.Timer {
+				fire = f
+				return time.AfterFunc(time.Hour, func() {})
+			}
+			c := newClient(t, s)
+
+			id := createDelivery(t, c)
+			order, _ := s.Order(id)
+			s.Close()
+			s.mu.Lock()
+			timers := len(s.timers)
+			s.mu.Unlock()
+			if timers != 0 {
+				t.Errorf("%d creation timers left after Close", timers)
+			}
+
+			if tt.fire {
+				fire()
+			}
+			if after, _ := s.Order(id); after.CreationStatus != order.CreationStatus {
+				t.Errorf("CreationStatus changed after Close: %v -> %v", order.CreationStatus, after.CreationStatus)
+			}
+			if n := len(receiver.eventTypes()); n != 0 {
+				t.Errorf("%d webhooks sent", n)
+			}
+		})
+	}
+}
+
+func TestNoWebhooksAfterClose(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	s := NewServer(WithCreationDelay(0), WithWebhook(receiver.URL, "secret"))
+	s.webhookFilter = iiko.WebHooksFilter{DeliveryOrderFilter: &iiko.DeliveryOrderFilter{}}
+	c := newClient(t, s)
+	id := createDelivery(t, c)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				s.SetDeliveryStatus(id, iiko.DeliveryStatusOnWay)
+			}
+		}()
+	}
+	s.Close()
+	sent := len(receiver.eventTypes())
+	wg.Wait()
+
+	if got := len(receiver.eventTypes()); got != sent {
+		t.Errorf("%d webhooks sent after Close", got-sent)
+	}
+}
@@ -0,0 +1,706 @@
+package iikotest
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teztar/iiko-go"
+)
+
+// AddOrganization adds an organization returned by /api/1/organizations.
+func (s *Server) AddOrganization(org iiko.Organization) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.organizations = append(s.organizations, org)
+}
+
+// AddTerminalGroup adds a terminal group that is alive.
+func (s *Server) AddTerminalGroup(group iiko.TerminalGroupItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.terminalGroups = append(s.terminalGroups, terminalGroup{item: group, alive: true})
+}
+
+// SetTerminalGroupAlive sets what /api/1/terminal_groups/is_alive reports for a terminal group.
+func (s *Server) SetTerminalGroupAlive(terminalGroupID uuid.UUID, alive bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.terminalGroups {
+		if s.terminalGroups[i].item.ID == terminalGroupID {
+			s.terminalGroups[i].alive = alive
+		}
+	}
+}
+
+// SetNomenclature sets the nomenclature of an organization.
+func (s *Server) SetNomenclature(organizationID uuid.UUID, nomenclature iiko.NomenclatureResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nomenclature[organizationID] = nomenclature
+}
+
+// SetMenu sets the response of /api/2/menu.
+func (s *Server) SetMenu(menu iiko.MenuResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.menu = menu
+}
+
+// SetMenuByID sets the response of /api/2/menu/by_id for an external menu.
+func (s *Server) SetMenuByID(externalMenuID string, menu iiko.MenuByIdResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.menus[externalMenuID] = menu
+}
+
+// SetStopList sets the stop list of an organization.
+func (s *Server) SetStopList(stopList iiko.TerminalGroupStopList) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopLists[stopList.OrganizationID] = stopList
+}
+
+// SetOrderValidator sets the validator of created deliveries. By default every
+// delivery is created successfully.
+func (s *Server) SetOrderValidator(v OrderValidator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.validator = v
+}
+
+// Order returns a delivery created through the fake.
+func (s *Server) Order(id uuid.UUID) (iiko.DeliveryOrderInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		return iiko.DeliveryOrderInfo{}, false
+	}
+	return *order, true
+}
+
+// Orders returns the deliveries created through the fake in creation order.
+func (s *Server) Orders() []iiko.DeliveryOrderInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]iiko.DeliveryOrderInfo, 0, len(s.orderIDs))
+	for _, id := range s.orderIDs {
+		orders = append(orders, *s.orders[id])
+	}
+	return orders
+}
+
+// SetDeliveryStatus changes the status of a delivery as if iikoFront did it,
+// and pushes a DeliveryOrderUpdate webhook.
+func (s *Server) SetDeliveryStatus(orderID uuid.UUID, status iiko.DeliveryStatus) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[orderID]
+	if !ok {
+		return false
+	}
+	s.setDeliveryStatus(order, status)
+	return true
+}
+
+// Settle completes the creation of every delivery still InProgress.
+func (s *Server) Settle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.pending {
+		s.completeCreation(id)
+	}
+}
+
+// AddCustomer adds a loyalty customer. A customer without ID gets a new one.
+func (s *Server) AddCustomer(customer iiko.CustomerInfoResponse) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if customer.Id == "" {
+		customer.Id = uuid.NewString()
+	}
+	s.putCustomer(&customer)
+	return customer.Id
+}
+
+// Customer returns a loyalty customer by ID.
+func (s *Server) Customer(id string) (iiko.CustomerInfoResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, ok := s.customers[id]
+	if !ok {
+		return iiko.CustomerInfoResponse{}, false
+	}
+	return *customer, true
+}
+
+// SetCustomerCategories sets the categories returned by /api/1/loyalty/iiko/customer_category.
+func (s *Server) SetCustomerCategories(categories []iiko.Category) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.categories = append([]iiko.Category(nil), categories...)
+}
+
+func (s *Server) handleOrganizations(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.OrganizationsRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	response := iiko.OrganizationsResponse{CorrelationID: uuid.New(), Organizations: []iiko.Organization{}}
+	for _, org := range s.organizations {
+		if len(req.OrganizationIDs) == 0 || containsID(req.OrganizationIDs, org.ID) {
+			response.Organizations = append(response.Organizations, org)
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) handleTerminalGroups(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.TerminalGroupsRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	response := iiko.TerminalGroupsResponse{CorrelationID: uuid.New(), TerminalGroups: []iiko.TerminalGroup{}}
+	for _, orgID := range req.OrganizationIDs {
+		group := iiko.TerminalGroup{OrganizationID: orgID, Items: []iiko.TerminalGroupItem{}}
+		for _, tg := range s.terminalGroups {
+			if tg.item.OrganizationID == orgID {
+				group.Items = append(group.Items, tg.item)
+			}
+		}
+		response.TerminalGroups = append(response.TerminalGroups, group)
+	}
+	return response, nil
+}
+
+func (s *Server) handleTerminalGroupsIsAlive(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.TerminalGroupsIsAliveRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	response := iiko.TerminalGroupsIsAliveResponse{CorrelationID: uuid.New(), IsAliveStatus: []iiko.TerminalGroupAliveInfo{}}
+	for _, tg := range s.terminalGroups {
+		if containsID(req.TerminalGroupIDs, tg.item.ID) {
+			response.IsAliveStatus = append(response.IsAliveStatus, iiko.TerminalGroupAliveInfo{
+				IsAlive:         tg.alive,
+				TerminalGroupID: tg.item.ID,
+				OrganizationID:  tg.item.OrganizationID,
+			})
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) handleNomenclature(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.NomenclatureRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	response := s.nomenclature[req.OrganizationID]
+	response.CorrelationID = uuid.New()
+	return response, nil
+}
+
+func (s *Server) handleMenu(body []byte) (interface{}, *iiko.ErrorResponse) {
+	response := s.menu
+	response.CorrelationId = uuid.New()
+	return response, nil
+}
+
+func (s *Server) handleMenuByID(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.MenuByIdRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	menu, ok := s.menus[req.ExternalMenuId]
+	if !ok {
+		return nil, badRequest("External menu not found: " + req.ExternalMenuId)
+	}
+	return menu, nil
+}
+
+func (s *Server) handleStopLists(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.StopListsRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	response := iiko.StopListsResponse{CorrelationID: uuid.New(), TerminalGroupStopLists: []iiko.TerminalGroupStopList{}}
+	for _, orgID := range req.OrganizationIDs {
+		if stopList, ok := s.stopLists[orgID]; ok {
+			response.TerminalGroupStopLists = append(response.TerminalGroupStopLists, stopList)
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) handleDeliveryCreate(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.DeliveryCreateRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	if req.Order.Id != nil {
+		id = *req.Order.Id
+	}
+	if _, exists := s.orders[id]; exists {
+		return nil, badRequest("Order with id " + id.String() + " already exists")
+	}
+
+	s.nextOrderNumber++
+	order := &iiko.DeliveryOrderInfo{
+		ID:             id,
+		OrganizationID: req.OrganizationId,
+		Timestamp:      int(time.Now().Unix()),
+		CreationStatus: iiko.OrderCreationStatusInProgress,
+		Order:          newDeliveryOrder(&req, s.nextOrderNumber),
+	}
+	if req.Order.ExternalNumber != nil {
+		order.ExternalNumber = *req.Order.ExternalNumber
+	}
+	s.orders[id] = order
+	s.orderIDs = append(s.orderIDs, id)
+
+	correlationID := uuid.New()
+	s.commands[correlationID] = &iiko.CommandsStatusResponse{
+		State:         iiko.InProgressCommandsStatusType,
+		CorrelationID: correlationID,
+	}
+
+	pending := pendingOrder{correlationID: correlationID}
+	if s.validator != nil {
+		pending.errorInfo = s.validator(&req)
+	}
+	if pending.errorInfo == nil && !s.terminalGroupAlive(req.TerminalGroupId) {
+		pending.errorInfo = &iiko.ErrorInfo{
+			Code:        "TerminalGroupDisabled",
+			Message:     "Terminal group " + req.TerminalGroupId.String() + " is not alive",
+			Description: "Terminal group " + req.TerminalGroupId.String() + " is not alive",
+		}
+	}
+	s.pending[id] = pending
+
+	if s.creationDelay >= 0 {
+		s.timers[id] = s.afterFunc(s.creationDelay, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if !s.closed {
+				s.completeCreation(id)
+			}
+		})
+	}
+
+	return iiko.DeliveryCreateResponse{CorrelationId: correlationID, OrderInfo: *order}, nil
+}
+
+// terminalGroupAlive reports whether a terminal group can take orders. Unknown
+// terminal groups can, so that tests don't have to seed them. s.mu must be held.
+func (s *Server) terminalGroupAlive(id uuid.UUID) bool {
+	for _, tg := range s.terminalGroups {
+		if tg.item.ID == id {
+			return tg.alive
+		}
+	}
+	return true
+}
+
+// completeCreation finishes the creation of a pending delivery. s.mu must be held.
+func (s *Server) completeCreation(id uuid.UUID) {
+	pending, ok := s.pending[id]
+	if !ok {
+		return
+	}
+	delete(s.pending, id)
+	if timer, ok := s.timers[id]; ok {
+		timer.Stop()
+		delete(s.timers, id)
+	}
+
+	order := s.orders[id]
+	command := s.commands[pending.correlationID]
+	order.Timestamp = int(time.Now().Unix())
+
+	if pending.errorInfo != nil {
+		order.CreationStatus = iiko.OrderCreationStatusError
+		order.ErrorInfo = *pending.errorInfo
+		command.State = iiko.ErrorCommandsStatusType
+		command.Error = string(pending.errorInfo.Code)
+		command.ErrorDescription = pending.errorInfo.Description
+		s.pushWebhook(iiko.DeliveryOrderErrorWebhookEvent, order.OrganizationID, order)
+		return
+	}
+
+	order.CreationStatus = iiko.OrderCreationStatusSuccess
+	order.PosID = uuid.New()
+	command.State = iiko.SuccessCommandsStatusType
+	s.pushWebhook(iiko.DeliveryOrderUpdateWebhookEvent, order.OrganizationID, order)
+}
+
+func newDeliveryOrder(req *iiko.DeliveryCreateRequest, number int) iiko.DeliveryOrder {
+	o := req.Order
+	order := iiko.DeliveryOrder{
+		Phone:           o.Phone,
+		Status:          iiko.DeliveryStatusUnconfirmed,
+		CompleteBefore:  o.CompleteBefore,
+		WhenCreated:     iiko.Time{Time: time.Now()},
+		Number:          number,
+		TerminalGroupID: req.TerminalGroupId,
+		ExternalData:    o.ExternalData,
+	}
+	if o.DeliveryPoint != nil {
+		order.DeliveryPoint = *o.DeliveryPoint
+	}
+	if o.Comment != nil {
+		order.Comment = *o.Comment
+	}
+	if o.SourceKey != nil {
+		order.SourceKey = *o.SourceKey
+	}
+	if o.OrderServiceType == nil || *o.OrderServiceType != "DeliveryPickUp" {
+		order.Customer.Type = "regular"
+	}
+	for _, item := range o.Items {
+		order.Sum = order.Sum.Add(item.Price.MulAmount(item.Amount))
+	}
+	return order
+}
+
+func (s *Server) handleDeliveriesByID(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.DeliveriesByIDRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	response := iiko.DeliveriesByIDResponse{Orders: []iiko.DeliveryOrderInfo{}}
+	for _, id := range req.OrderIDs {
+		if order, ok := s.orders[id]; ok && order.OrganizationID == req.OrganizationID {
+			response.Orders = append(response.Orders, *order)
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) handleUpdateOrderDeliveryStatus(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.UpdateOrderDeliveryStatusRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	order, ok := s.orders[req.OrderId]
+	if !ok || order.OrganizationID != req.OrganizationId {
+		return nil, badRequest("Order not found: " + req.OrderId.String())
+	}
+	if order.CreationStatus != iiko.OrderCreationStatusSuccess {
+		return nil, badRequest("Order is not created: " + req.OrderId.String())
+	}
+	s.setDeliveryStatus(order, req.Status)
+
+	correlationID := uuid.New()
+	s.commands[correlationID] = &iiko.CommandsStatusResponse{
+		State:         iiko.SuccessCommandsStatusType,
+		CorrelationID: correlationID,
+	}
+	return iiko.UpdateOrderDeliveryStatusResponse{CorrelationId: correlationID}, nil
+}
+
+// setDeliveryStatus changes the status of order and pushes a webhook. s.mu must be held.
+func (s *Server) setDeliveryStatus(order *iiko.DeliveryOrderInfo, status iiko.DeliveryStatus) {
+	order.Order.Status = status
+	order.Timestamp = int(time.Now().Unix())
+	s.pushWebhook(iiko.DeliveryOrderUpdateWebhookEvent, order.OrganizationID, order)
+}
+
+func (s *Server) handleCommandsStatus(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.CommandsStatusRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	command, ok := s.commands[req.CorrelationID]
+	if !ok {
+		return nil, badRequest("Command not found: " + req.CorrelationID.String())
+	}
+	return command, nil
+}
+
+func (s *Server) handleWebhookSettings(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.WebhookSettingsRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	return iiko.WebhookSettingsResponse{
+		CorrelationId:  uuid.New(),
+		ApiLoginName:   s.apiLogin,
+		WebHooksUri:    s.webhookURL,
+		AuthToken:      s.webhookAuthToken,
+		WebHooksFilter: s.webhookFilter,
+	}, nil
+}
+
+func (s *Server) handleWebhookUpdateSettings(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.WebhookUpdateSettingsRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	s.webhookURL = req.WebHooksUri
+	s.webhookAuthToken = req.AuthToken
+	s.webhookFilter = req.WebHooksFilter
+	return iiko.WebhookUpdateSettingsResponse{CorrelationId: uuid.New()}, nil
+}
+
+// putCustomer stores customer. s.mu must be held.
+func (s *Server) putCustomer(customer *iiko.CustomerInfoResponse) {
+	if _, exists := s.customers[customer.Id]; !exists {
+		s.customerIDs = append(s.customerIDs, customer.Id)
+	}
+	s.customers[customer.Id] = customer
+}
+
+// findCustomer looks a customer up like /customer/info does. s.mu must be held.
+func (s *Server) findCustomer(req *iiko.CustomerInfoRequest) *iiko.CustomerInfoResponse {
+	for _, id := range s.customerIDs {
+		customer := s.customers[id]
+		switch req.Type {
+		case iiko.CustomerInfoTypeId:
+			if req.Id != nil && customer.Id == *req.Id {
+				return customer
+			}
+		case iiko.CustomerInfoTypePhone:
+			if req.Phone != nil && equalPtr(customer.Phone, *req.Phone) {
+				return customer
+			}
+		case iiko.CustomerInfoTypeEmail:
+			if req.Email != nil && equalPtr(customer.Email, *req.Email) {
+				return customer
+			}
+		case iiko.CustomerInfoTypeCardTrack, iiko.CustomerInfoTypeCardNumber:
+			for _, card := range customer.Cards {
+				if (req.CardTrack != nil && card.Track == *req.CardTrack) ||
+					(req.CardNumber != nil && card.Number == *req.CardNumber) {
+					return customer
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleCustomerInfo(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.CustomerInfoRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	customer := s.findCustomer(&req)
+	if customer == nil || (customer.IsDeleted != nil && *customer.IsDeleted) {
+		return nil, badRequest("There is no user with such credentials")
+	}
+	return customer, nil
+}
+
+func (s *Server) handleCreateOrUpdate(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.CreateOrUpdateRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	var customer *iiko.CustomerInfoResponse
+	if req.Id != nil {
+		customer = s.customers[*req.Id]
+	}
+	if customer == nil && req.Phone != nil {
+		customer = s.findCustomer(&iiko.CustomerInfoRequest{Type: iiko.CustomerInfoTypePhone, Phone: req.Phone})
+	}
+	if customer == nil {
+		customer = &iiko.CustomerInfoResponse{Id: uuid.NewString()}
+		if req.Id != nil {
+			customer.Id = *req.Id
+		}
+	}
+
+	setIfPresent(&customer.Phone, req.Phone)
+	setIfPresent(&customer.Name, req.Name)
+	setIfPresent(&customer.MiddleName, req.MiddleName)
+	setIfPresent(&customer.Surname, req.SurName)
+	setIfPresent(&customer.Birthday, req.Birthday)
+	setIfPresent(&customer.Email, req.Email)
+	setIfPresent(&customer.ReferrerId, req.ReferrerId)
+	setIfPresent(&customer.UserData, req.UserData)
+	customer.Sex = req.Sex
+	customer.ConsentStatus = req.ConsentStatus
+	if req.ShouldReceivePromoActionsInfo != nil {
+		customer.ShouldReceivePromoActionsInfo = req.ShouldReceivePromoActionsInfo
+	}
+	if req.CardTrack != nil || req.CardNumber != nil {
+		card := iiko.Card{Id: uuid.NewString()}
+		setIfPresentString(&card.Track, req.CardTrack)
+		setIfPresentString(&card.Number, req.CardNumber)
+		customer.Cards = append(customer.Cards, card)
+	}
+
+	s.putCustomer(customer)
+	return iiko.CreateOrUpdateResponse{Id: customer.Id}, nil
+}
+
+func (s *Server) handleCardAdd(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.CardAddRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	customer, ok := s.customers[req.CustomerId]
+	if !ok {
+		return nil, badRequest("Customer not found: " + req.CustomerId)
+	}
+	customer.Cards = append(customer.Cards, iiko.Card{
+		Id:     uuid.NewString(),
+		Track:  req.CardTrack,
+		Number: req.CardNumber,
+	})
+	return iiko.CardAddResponse{}, nil
+}
+
+func (s *Server) handleCustomerCategories(body []byte) (interface{}, *iiko.ErrorResponse) {
+	categories := append([]iiko.Category{}, s.categories...)
+	return iiko.CustomerCategoriesResponse{GuestCategories: categories}, nil
+}
+
+func (s *Server) handleCustomerCategoryAdd(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.CustomerCategoryAddRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	customer, category, errResp := s.customerCategory(req.CustomerId, req.CategoryId)
+	if errResp != nil {
+		return nil, errResp
+	}
+	for _, c := range customer.Categories {
+		if c.Id == category.Id {
+			return struct{}{}, nil
+		}
+	}
+	customer.Categories = append(customer.Categories, category)
+	return struct{}{}, nil
+}
+
+func (s *Server) handleCustomerCategoryRemove(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.CustomerCategoryRemoveRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	customer, category, errResp := s.customerCategory(req.CustomerId, req.CategoryId)
+	if errResp != nil {
+		return nil, errResp
+	}
+	kept := customer.Categories[:0]
+	for _, c := range customer.Categories {
+		if c.Id != category.Id {
+			kept = append(kept, c)
+		}
+	}
+	customer.Categories = kept
+	return struct{}{}, nil
+}
+
+// customerCategory looks up a customer and a category by ID. s.mu must be held.
+func (s *Server) customerCategory(customerID, categoryID string) (*iiko.CustomerInfoResponse, iiko.Category, *iiko.ErrorResponse) {
+	customer, ok := s.customers[customerID]
+	if !ok {
+		return nil, iiko.Category{}, badRequest("Customer not found: " + customerID)
+	}
+	for _, category := range s.categories {
+		if category.Id == categoryID {
+			return customer, category, nil
+		}
+	}
+	return nil, iiko.Category{}, badRequest("Category not found: " + categoryID)
+}
+
+func (s *Server) handleDeleteCustomers(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.DeleteCustomersRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	response := iiko.DeleteCustomersResponse{Total: len(req.CustomerIds)}
+	for _, id := range req.CustomerIds {
+		if customer, ok := s.customers[id.String()]; ok {
+			deleted := true
+			customer.IsDeleted = &deleted
+			response.Deleted++
+		} else {
+			response.NotFound++
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) handleRestoreCustomers(body []byte) (interface{}, *iiko.ErrorResponse) {
+	var req iiko.RestoreCustomersRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	response := iiko.RestoreCustomersResponse{Total: len(req.CustomerIds)}
+	for _, id := range req.CustomerIds {
+		if customer, ok := s.customers[id.String()]; ok {
+			deleted := false
+			customer.IsDeleted = &deleted
+			response.Restored++
+		} else {
+			response.NotFound++
+		}
+	}
+	return response, nil
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func equalPtr(p *string, v string) bool {
+	return p != nil && *p == v
+}
+
+func setIfPresent[T any](dst **T, v *T) {
+	if v != nil {
+		value := *v
+		*dst = &value
+	}
+}
+
+func setIfPresentString(dst *string, v *string) {
+	if v != nil {
+		*dst = *v
+	}
+}
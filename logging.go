@@ -0,0 +1,99 @@
+package iiko
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+// WithLogger makes the Client log every API call to l: endpoint, status,
+// latency, attempts and iiko correlationId. With the debug level enabled it
+// also logs request and response bodies, with secrets and customer personal
+// data redacted (see RedactBody).
+func WithLogger(l *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// logCalls is the middleware installed by WithLogger.
+func logCalls(l *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			if l.Enabled(ctx, slog.LevelDebug) {
+				l.DebugContext(ctx, "iiko request",
+					slog.String("endpoint", call.Endpoint),
+					slog.String("body", string(RedactBody(call.Endpoint, call.Body))),
+				)
+			}
+
+			err := next(ctx, call)
+
+			attrs := []slog.Attr{
+				slog.String("endpoint", call.Endpoint),
+				slog.Int("status", call.StatusCode),
+				slog.Duration("latency", call.Duration),
+				slog.Int("attempts", call.Attempts),
+			}
+			if id := correlationID(call.ResponseBody); id != "" {
+				attrs = append(attrs, slog.String("correlationId", id))
+			}
+
+			if l.Enabled(ctx, slog.LevelDebug) && len(call.ResponseBody) > 0 {
+				l.DebugContext(ctx, "iiko response",
+					slog.String("endpoint", call.Endpoint),
+					slog.String("body", string(RedactBody(call.Endpoint, call.ResponseBody))),
+				)
+			}
+
+			if err != nil {
+				l.LogAttrs(ctx, slog.LevelError, "iiko call failed", append(attrs, slog.String("error", err.Error()))...)
+				return err
+			}
+
+			l.LogAttrs(ctx, slog.LevelInfo, "iiko call", attrs...)
+			return nil
+		}
+	}
+}
+
+// correlationID extracts the operation ID iiko puts into almost every response body.
+func correlationID(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v struct {
+		CorrelationID string `json:"correlationId"`
+	}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return ""
+	}
+
+	return v.CorrelationID
+}
+
+// logEvent logs one webhook event handled by WebhookServer.
+func logEvent(ctx context.Context, l *slog.Logger, event *WebhookEvent, latency time.Duration, err error) {
+	attrs := []slog.Attr{
+		slog.String("eventType", string(event.EventType)),
+		slog.String("organizationId", event.OrganizationID.String()),
+		slog.String("correlationId", event.CorrelationID.String()),
+		slog.Duration("latency", latency),
+	}
+
+	if l.Enabled(ctx, slog.LevelDebug) {
+		l.DebugContext(ctx, "iiko webhook event",
+			slog.String("eventType", string(event.EventType)),
+			slog.String("eventInfo", string(RedactBody("", event.EventInfo))),
+		)
+	}
+
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "iiko webhook failed", append(attrs, slog.String("error", err.Error()))...)
+		return
+	}
+
+	l.LogAttrs(ctx, slog.LevelInfo, "iiko webhook", attrs...)
+}
@@ -0,0 +1,150 @@
+package iiko
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		body     string
+		want     string
+	}{
+		{"empty", "", "", ""},
+		{"not json", "", "<html>", Redacted},
+		{"json with trailing data", "", `{"phone":"+7"} +79990000000`, Redacted},
+		{"api login", "/api/1/access_token", `{"apiLogin":"secret"}`, `{"apiLogin":"[REDACTED]"}`},
+		{"token", "/api/1/access_token", `{"correlationId":"x","token":"abc"}`, `{"correlationId":"x","token":"[REDACTED]"}`},
+		{"nested contacts", "/api/1/deliveries/create",
+			`{"order":{"phone":"+79990000000","customer":{"name":"Ivan","email":"a@b.c"}}}`,
+			`{"order":{"customer":{"email":"[REDACTED]","name":"[REDACTED]"},"phone":"[REDACTED]"}}`},
+		{"product name kept", "/api/1/nomenclature", `{"products":[{"name":"Pizza","price":1.10}]}`, `{"products":[{"name":"Pizza","price":1.10}]}`},
+		{"customer endpoint", "/api/1/loyalty/iiko/customer/info", `{"name":"Ivan","surname":"Petrov"}`, `{"name":"[REDACTED]","surname":"[REDACTED]"}`},
+		{"null kept", "", `{"phone":null}`, `{"phone":null}`},
+		{"card lookup", "/api/1/loyalty/iiko/customer/info", `{"cardNumber":"123","cardTrack":"456","type":"cardNumber"}`, `{"cardNumber":"[REDACTED]","cardTrack":"[REDACTED]","type":"cardNumber"}`},
+		{"customer cards", "/api/1/loyalty/iiko/customer/info",
+			`{"id":"c","cards":[{"id":"1","track":"456","number":"123","validToDate":"2030-01-01"}]}`,
+			`{"cards":[{"id":"1","number":"[REDACTED]","track":"[REDACTED]","validToDate":"2030-01-01"}],"id":"c"}`},
+		{"order number kept", "/api/1/deliveries/by_id", `{"orders":[{"order":{"number":42}}]}`, `{"orders":[{"order":{"number":42}}]}`},
+		{"birthday keeps its type", "/api/1/loyalty/iiko/customer/info", `{"birthday":"1990-05-17 00:00:00.000"}`, `{"birthday":"` + RedactedTime + `"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(RedactBody(tt.endpoint, []byte(tt.body))); got != tt.want {
+				t.Errorf("RedactBody = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	h := http.Header{"Authorization": {"Bearer abc"}, "Timeout": {"15"}}
+	got := RedactHeader(h)
+	if got.Get("Authorization") != "Bearer "+Redacted || got.Get("Timeout") != "15" {
+		t.Errorf("RedactHeader = %v", got)
+	}
+	if h.Get("Authorization") != "Bearer abc" {
+		t.Error("RedactHeader modified its argument")
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes by slog handlers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) records(t *testing.T) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestLogCalls(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    slog.Level
+		status   int
+		wantMsgs []string
+		wantLvl  string
+	}{
+		{"info", slog.LevelInfo, http.StatusOK, []string{"iiko call"}, "INFO"},
+		{"debug", slog.LevelDebug, http.StatusOK, []string{"iiko request", "iiko response", "iiko call"}, "INFO"},
+		{"failure", slog.LevelInfo, http.StatusBadRequest, []string{"iiko call failed"}, "ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, tt.status, `{"correlationId":"`+testCorrelationID+`","phone":"+79990000000"}`)
+			})
+
+			var buf syncBuffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: tt.level}))
+			c := newTestClient(t, srv, WithLogger(logger))
+
+			_, _ = c.Organizations(context.Background(), &OrganizationsRequest{})
+
+			var msgs []string
+			var last map[string]any
+			for _, r := range buf.records(t) {
+				if r["endpoint"] != "/api/1/organizations" {
+					continue
+				}
+				msgs = append(msgs, r["msg"].(string))
+				last = r
+			}
+			if strings.Join(msgs, ",") != strings.Join(tt.wantMsgs, ",") {
+				t.Fatalf("messages = %v, want %v", msgs, tt.wantMsgs)
+			}
+			if last["level"] != tt.wantLvl || last["correlationId"] != testCorrelationID || last["status"] != float64(tt.status) {
+				t.Errorf("record = %v", last)
+			}
+			if strings.Contains(buf.buf.String(), "+79990000000") {
+				t.Error("phone number leaked into the log")
+			}
+		})
+	}
+}
+
+func TestCorrelationID(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"", ""},
+		{"not json", ""},
+		{`{"correlationId":"abc"}`, "abc"},
+		{`[1,2]`, ""},
+	}
+	for _, tt := range tests {
+		if got := correlationID([]byte(tt.body)); got != tt.want {
+			t.Errorf("correlationID(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
@@ -2,92 +2,182 @@ package iiko
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 var ErrMissingToken = errors.New("missing API token")
 
-// doRequest performs a single POST to iikoCloud. When requiresAuth is set and
-// iiko answers 401 (token expired/revoked), it refreshes the token once and
-// retries the request a single time before giving up.
+// doRequest marshals body and runs the call through the Client's middleware
+// chain down to execute.
 func (c *Client) doRequest(
+	ctx context.Context,
 	requiresAuth bool,
 	endpoint string,
 	body interface{},
 	response interface{},
 	opts ...Option,
 ) error {
-	if requiresAuth && c.getToken() == "" {
-		return ErrMissingToken
-	}
-
-	// Marshal json body for request once; reused on retry.
+	// Marshal json body for request once; reused on retries.
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
 
+	return c.handler()(ctx, &Call{
+		Endpoint:     endpoint,
+		RequiresAuth: requiresAuth,
+		Body:         jsonBody,
+		Response:     response,
+		Options:      opts,
+	})
+}
+
+// execute performs a POST to iikoCloud. When the call requires auth and
+// iiko answers 401 (token expired/revoked), it refreshes the token once and
+// retries the request a single time before giving up. Transient failures are
+// retried according to the Client's RetryPolicy, if any.
+//
+// ctx is attached to every outgoing request, including the token refresh, so
+// cancelling it aborts whichever of them is in flight.
+func (c *Client) execute(ctx context.Context, call *Call) (err error) {
+	// token is the access token the last request was sent with.
+	var token string
+	// lastReq is the last request sent, kept for exchange capture.
+	var lastReq *http.Request
+
+	start := time.Now()
+	defer func() {
+		call.Duration = time.Since(start)
+		c.captureExchange(ctx, call, lastReq, err)
+	}()
+
 	send := func() (*http.Response, error) {
-		req, reqErr := http.NewRequest(http.MethodPost, c.baseURL+endpoint, bytes.NewBuffer(jsonBody))
+		if call.RequiresAuth {
+			var tokenErr error
+			if token, tokenErr = c.validToken(ctx); tokenErr != nil {
+				return nil, tokenErr
+			}
+		}
+
+		if waitErr := c.waitRateLimit(ctx, call.Endpoint); waitErr != nil {
+			return nil, waitErr
+		}
+
+		baseURL := c.baseURLs.pick()
+		req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+call.Endpoint, bytes.NewReader(call.Body))
 		if reqErr != nil {
 			return nil, reqErr
 		}
 
 		req.Header.Set("Content-Type", "application/json; charset=UTF-8")
-		if requiresAuth {
-			req.Header.Set("Authorization", "Bearer "+c.getToken())
+		if call.RequiresAuth {
+			req.Header.Set("Authorization", "Bearer "+token)
 		}
 		req.Header.Set("Timeout", strconv.Itoa(int(c.timeout.Seconds())))
 
-		for _, opt := range opts {
+		for _, opt := range call.Options {
 			opt.Apply(req)
 		}
+		lastReq = req
 
-		return c.httpClient.Do(req)
+		resp, doErr := c.httpClient.Do(req)
+		if ctx.Err() == nil {
+			c.baseURLs.report(baseURL, doErr == nil && resp.StatusCode < http.StatusInternalServerError)
+		}
+		return resp, doErr
 	}
 
-	resp, err := send()
-	if err != nil {
-		return err
+	// sendOnce performs one attempt. Token expired/revoked — refresh once and
+	// resend within the same attempt.
+	sendOnce := func() (*http.Response, error) {
+		resp, sendErr := send()
+		if sendErr != nil {
+			return nil, sendErr
+		}
+
+		if call.RequiresAuth && resp.StatusCode == http.StatusUnauthorized {
+			resp.Body.Close()
+			if refreshErr := c.refreshTokenAfter(ctx, token); refreshErr != nil {
+				return nil, refreshErr
+			}
+			if c.metrics != nil {
+				c.metrics.ObserveUnauthorizedRetry(call.Endpoint)
+			}
+			return send()
+		}
+
+		return resp, nil
 	}
 
-	// Token expired/revoked — refresh once and retry.
-	if requiresAuth && resp.StatusCode == http.StatusUnauthorized {
-		resp.Body.Close()
-		if refreshErr := c.refreshToken(); refreshErr != nil {
-			return refreshErr
+	var resp *http.Response
+	attempts := c.retryPolicy.attempts(call.Endpoint, call.Options)
+	for attempt := 1; ; attempt++ {
+		call.Attempts = attempt
+		resp, err = sendOnce()
+		if attempt >= attempts || !isTransient(ctx, resp, err) {
+			break
 		}
-		resp, err = send()
-		if err != nil {
-			return err
+
+		wait := c.retryPolicy.backoff(attempt, resp)
+		discardResponse(resp)
+		if sleepErr := sleepContext(ctx, wait); sleepErr != nil {
+			return wrapTransportError(call.Endpoint, sleepErr)
 		}
 	}
+	if err != nil {
+		return wrapTransportError(call.Endpoint, err)
+	}
 
 	defer resp.Body.Close()
 
+	call.StatusCode = resp.StatusCode
+	call.ResponseHeader = resp.Header
+	call.ResponseBody, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return wrapTransportError(call.Endpoint, err)
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		var errorResponse ErrorResponse
-		if err = json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil {
-			return err
+		errorResponse := &ErrorResponse{
+			StatusCode: resp.StatusCode,
+			Endpoint:   call.Endpoint,
+			Header:     resp.Header,
+			Body:       call.ResponseBody,
 		}
-		errorResponse.StatusCode = resp.StatusCode
-		return &errorResponse
+		// A body that is not iiko JSON (empty 429, HTML 502) must not hide
+		// the status code, so a decoding error is not returned.
+		_ = json.Unmarshal(call.ResponseBody, errorResponse)
+		return errorResponse
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+	err = json.NewDecoder(bytes.NewReader(call.ResponseBody)).Decode(call.Response)
+	if c.drift != nil {
+		c.detectDrift(ctx, call)
+		if err != nil && c.drift.tolerates(err) {
+			err = nil
+		}
+	}
+	if err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (c *Client) post(requiresAuth bool, endpoint string, body interface{}, response interface{}, opts ...Option) error {
-	return c.doRequest(requiresAuth, endpoint, body, response, opts...)
+func (c *Client) post(ctx context.Context, requiresAuth bool, endpoint string, body interface{}, response interface{}, opts ...Option) error {
+	return c.doRequest(ctx, requiresAuth, endpoint, body, response, opts...)
 }
 
-func (c *Client) Post(requiresAuth bool, endpoint string, body interface{}, response interface{}, opts ...Option) error {
-	return c.doRequest(requiresAuth, endpoint, body, response, opts...)
+// Post calls an arbitrary iiko API endpoint, decoding the response into response.
+//
+// Deprecated: declare the endpoint with NewEndpoint and use Endpoint.Call,
+// which is typed and registers the endpoint metadata.
+func (c *Client) Post(ctx context.Context, requiresAuth bool, endpoint string, body interface{}, response interface{}, opts ...Option) error {
+	return c.doRequest(ctx, requiresAuth, endpoint, body, response, opts...)
 }
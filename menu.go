@@ -1,6 +1,7 @@
 package iiko
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/google/uuid"
@@ -53,7 +54,7 @@ type MenuPrice struct {
 	// Organization ID
 	OrganizationId string `json:"organizationId"`
 	// Price value
-	Price float64 `json:"price"`
+	Price Money `json:"price"`
 }
 
 // MenuPriceWithTax represents price information with organizations and tax category for menu
@@ -61,7 +62,7 @@ type MenuPriceWithTax struct {
 	// Organization IDs
 	OrganizationId string `json:"organizationId"`
 	// Price value
-	Price *float64 `json:"price"`
+	Price *Money `json:"price"`
 }
 
 // AllergenGroup represents allergen group information
@@ -146,9 +147,10 @@ type CustomerTagGroupSelection struct {
 type Interval struct {
 	// Organization ID
 	OrganizationId uuid.UUID `json:"organizationId"`
-	// From time
+	// From time. Like Schedule.Begin, a time of day without a date, so it is
+	// not a Time.
 	FromTime string `json:"fromTime"`
-	// To time
+	// To time, a time of day like FromTime
 	ToTime string `json:"toTime"`
 }
 
@@ -233,7 +235,7 @@ type ComboGroupItem struct {
 	// Size ID
 	SizeId string `json:"sizeId"`
 	// Price modification amount
-	PriceModificationAmount float64 `json:"priceModificationAmount"`
+	PriceModificationAmount Money `json:"priceModificationAmount"`
 	// Sizes
 	Sizes []ComboItemSize `json:"sizes"`
 }
@@ -257,7 +259,7 @@ type MenuCombo struct {
 	// Combo name
 	Name string `json:"name"`
 	// Combo price
-	Price string `json:"price"`
+	Price Money `json:"price"`
 	// Groups
 	Groups []ComboGroup `json:"groups"`
 	// Images
@@ -269,9 +271,9 @@ type MenuCombo struct {
 	// Price strategy
 	PriceStrategy string `json:"priceStrategy"`
 	// Start date
-	StartDate string `json:"startDate"`
+	StartDate Time `json:"startDate"`
 	// Expiration date
-	ExpirationDate string `json:"expirationDate"`
+	ExpirationDate Time `json:"expirationDate"`
 	// Combo ID
 	Id uuid.UUID `json:"id"`
 }
@@ -545,28 +547,20 @@ type MenuByIdResponse struct {
 	ComboCategories []MenuComboCategory `json:"comboCategories"`
 }
 
+var menuEndpoint = NewEndpoint[struct{}, MenuResponse](EndpointInfo{Path: "/api/2/menu", RequiresAuth: true, Idempotent: true})
+
 // Menu Retrieve external menus and price categories
 //
 // iiko API: /api/2/menu
-func (c *Client) Menu(opts ...Option) (*MenuResponse, error) {
-	var response MenuResponse
-
-	if err := c.post(true, "/api/2/menu", nil, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) Menu(ctx context.Context, opts ...Option) (*MenuResponse, error) {
+	return menuEndpoint.Call(ctx, c, nil, opts...)
 }
 
+var menuByIdEndpoint = NewEndpoint[MenuByIdRequest, MenuByIdResponse](EndpointInfo{Path: "/api/2/menu/by_id", RequiresAuth: true, Idempotent: true})
+
 // MenuById Retrieve menu by external menu ID
 //
 // iiko API: /api/2/menu/by_id
-func (c *Client) MenuById(req *MenuByIdRequest, opts ...Option) (*MenuByIdResponse, error) {
-	var response MenuByIdResponse
-
-	if err := c.post(true, "/api/2/menu/by_id", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) MenuById(ctx context.Context, req *MenuByIdRequest, opts ...Option) (*MenuByIdResponse, error) {
+	return menuByIdEndpoint.Call(ctx, c, req, opts...)
 }
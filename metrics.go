@@ -0,0 +1,70 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// MetricsCollector receives measurements of the Client and the WebhookServer.
+// Implementations must be safe for concurrent use. PrometheusMetrics is the
+// one shipped with the package.
+type MetricsCollector interface {
+	// ObserveCall is called once per API call. errorCode is empty on success,
+	// ErrorResponse.ErrorField for iiko API errors and "transport" otherwise.
+	ObserveCall(endpoint string, statusCode int, errorCode string, duration time.Duration)
+
+	// ObserveTokenRefresh is called after every access token request.
+	ObserveTokenRefresh(err error)
+
+	// ObserveUnauthorizedRetry is called when a call is resent after a 401.
+	ObserveUnauthorizedRetry(endpoint string)
+
+	// ObserveWebhookEvent is called once per event handled by WebhookServer.
+	ObserveWebhookEvent(eventType WebhookEventType, duration time.Duration, err error)
+}
+
+// Error codes reported to MetricsCollector.ObserveCall besides iiko ones.
+const (
+	MetricsErrorTransport   = "transport"
+	MetricsErrorUnknown     = "unknown"
+	MetricsErrorCircuitOpen = "circuit_open"
+)
+
+// WithMetrics makes the Client report its measurements to m.
+func WithMetrics(m MetricsCollector) ClientOption {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// measureCalls is the middleware installed by WithMetrics.
+func measureCalls(m MetricsCollector) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			start := time.Now()
+			err := next(ctx, call)
+			m.ObserveCall(call.Endpoint, call.StatusCode, metricsErrorCode(err), time.Since(start))
+			return err
+		}
+	}
+}
+
+func metricsErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var apiErr *ErrorResponse
+	if errors.As(err, &apiErr) {
+		if apiErr.ErrorField != "" {
+			return apiErr.ErrorField
+		}
+		return MetricsErrorUnknown
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return MetricsErrorCircuitOpen
+	}
+
+	return MetricsErrorTransport
+}
@@ -0,0 +1,210 @@
+package iiko
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the histogram buckets, in seconds, used by
+// NewPrometheusMetrics when none are given.
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 15, 30}
+
+// PrometheusMetrics is a MetricsCollector that keeps its measurements in
+// memory and serves them in the Prometheus text exposition format:
+//
+//	http.Handle("/metrics", metrics)
+type PrometheusMetrics struct {
+	mu      sync.Mutex
+	buckets []float64
+
+	requests           map[string]uint64 // endpoint, status
+	requestErrors      map[string]uint64 // endpoint, status, error
+	requestDuration    map[string]*histogram
+	tokenRefreshes     map[string]uint64 // result
+	unauthorizedRetry  map[string]uint64 // endpoint
+	webhookEvents      map[string]uint64 // event_type, result
+	webhookHandlerTime map[string]*histogram
+	schemaDrift        map[string]uint64 // endpoint, kind, path
+}
+
+// NewPrometheusMetrics creates an empty collector. buckets are the upper bounds
+// of latency histograms in seconds, DefaultLatencyBuckets if none are given.
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &PrometheusMetrics{
+		buckets:            buckets,
+		requests:           make(map[string]uint64),
+		requestErrors:      make(map[string]uint64),
+		requestDuration:    make(map[string]*histogram),
+		tokenRefreshes:     make(map[string]uint64),
+		unauthorizedRetry:  make(map[string]uint64),
+		webhookEvents:      make(map[string]uint64),
+		webhookHandlerTime: make(map[string]*histogram),
+		schemaDrift:        make(map[string]uint64),
+	}
+}
+
+// ObserveCall implements MetricsCollector.
+func (m *PrometheusMetrics) ObserveCall(endpoint string, statusCode int, errorCode string, duration time.Duration) {
+	status := strconv.Itoa(statusCode)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[labels("endpoint", endpoint, "status", status)]++
+	if errorCode != "" {
+		m.requestErrors[labels("endpoint", endpoint, "status", status, "error", errorCode)]++
+	}
+	m.observe(m.requestDuration, labels("endpoint", endpoint), duration)
+}
+
+// ObserveTokenRefresh implements MetricsCollector.
+func (m *PrometheusMetrics) ObserveTokenRefresh(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tokenRefreshes[labels("result", result(err))]++
+}
+
+// ObserveUnauthorizedRetry implements MetricsCollector.
+func (m *PrometheusMetrics) ObserveUnauthorizedRetry(endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.unauthorizedRetry[labels("endpoint", endpoint)]++
+}
+
+// ObserveWebhookEvent implements MetricsCollector.
+func (m *PrometheusMetrics) ObserveWebhookEvent(eventType WebhookEventType, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.webhookEvents[labels("event_type", string(eventType), "result", result(err))]++
+	m.observe(m.webhookHandlerTime, labels("event_type", string(eventType)), duration)
+}
+
+// ObserveSchemaDrift implements DriftObserver.
+func (m *PrometheusMetrics) ObserveSchemaDrift(endpoint string, kind DriftKind, path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.schemaDrift[labels("endpoint", endpoint, "kind", string(kind), "path", path)]++
+}
+
+// ServeHTTP writes all metrics in the Prometheus text exposition format.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WriteText(w)
+}
+
+// WriteText writes all metrics in the Prometheus text exposition format to w.
+func (m *PrometheusMetrics) WriteText(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	writeCounter(&b, "iiko_requests_total", "iikoCloud API calls.", m.requests)
+	writeCounter(&b, "iiko_request_errors_total", "Failed iikoCloud API calls by error code.", m.requestErrors)
+	writeHistogram(&b, "iiko_request_duration_seconds", "iikoCloud API call latency.", m.buckets, m.requestDuration)
+	writeCounter(&b, "iiko_token_refreshes_total", "Access token requests by result.", m.tokenRefreshes)
+	writeCounter(&b, "iiko_unauthorized_retries_total", "Calls resent after a 401 and token refresh.", m.unauthorizedRetry)
+	writeCounter(&b, "iiko_webhook_events_total", "Handled webhook events by result.", m.webhookEvents)
+	writeHistogram(&b, "iiko_webhook_handler_duration_seconds", "Webhook handlers latency.", m.buckets, m.webhookHandlerTime)
+	writeCounter(&b, "iiko_schema_drift_total", "Responses that differ from the library model, by JSON path.", m.schemaDrift)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (m *PrometheusMetrics) observe(hs map[string]*histogram, key string, d time.Duration) {
+	h, ok := hs[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		hs[key] = h
+	}
+
+	v := d.Seconds()
+	for i, upper := range m.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// histogram holds cumulative bucket counts.
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// labels renders label pairs as they appear inside the braces of a sample.
+func labels(kv ...string) string {
+	var b strings.Builder
+	for i := 0; i < len(kv); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(kv[i])
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(kv[i+1]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeCounter(b *strings.Builder, name, help string, values map[string]uint64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(b, "%s{%s} %d\n", name, key, values[key])
+	}
+}
+
+func writeHistogram(b *strings.Builder, name, help string, buckets []float64, values map[string]*histogram) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, key := range sortedKeys(values) {
+		h := values[key]
+		for i, upper := range buckets {
+			le := strconv.FormatFloat(upper, 'g', -1, 64)
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %d\n", name, key, le, h.counts[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, key, h.count)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, key, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, key, h.count)
+	}
+}
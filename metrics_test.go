@@ -0,0 +1,133 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMetricsErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"success", nil, ""},
+		{"iiko error", &ErrorResponse{ErrorField: "ORDER_NOT_FOUND"}, "ORDER_NOT_FOUND"},
+		{"iiko error without code", &ErrorResponse{StatusCode: http.StatusBadGateway}, MetricsErrorUnknown},
+		{"wrapped iiko error", fmt.Errorf("create: %w", &ErrorResponse{ErrorField: "X"}), "X"},
+		{"circuit open", fmt.Errorf("call: %w", ErrCircuitOpen), MetricsErrorCircuitOpen},
+		{"transport", errors.New("connection reset"), MetricsErrorTransport},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := metricsErrorCode(tt.err); got != tt.want {
+				t.Errorf("metricsErrorCode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrometheusMetricsText(t *testing.T) {
+	m := NewPrometheusMetrics(1, 0.1)
+	m.ObserveCall("/api/1/organizations", 200, "", 50*time.Millisecond)
+	m.ObserveCall("/api/1/organizations", 400, "BAD", 500*time.Millisecond)
+	m.ObserveTokenRefresh(nil)
+	m.ObserveTokenRefresh(errors.New("down"))
+	m.ObserveUnauthorizedRetry("/api/1/organizations")
+	m.ObserveWebhookEvent(DeliveryOrderUpdateWebhookEvent, 2*time.Second, nil)
+	m.ObserveSchemaDrift("/api/1/organizations", DriftUnknownField, `a"b`)
+
+	var b strings.Builder
+	if err := m.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	text := b.String()
+
+	for _, want := range []string{
+		"# TYPE iiko_requests_total counter\n",
+		`iiko_requests_total{endpoint="/api/1/organizations",status="200"} 1` + "\n",
+		`iiko_request_errors_total{endpoint="/api/1/organizations",status="400",error="BAD"} 1` + "\n",
+		`iiko_request_duration_seconds_bucket{endpoint="/api/1/organizations",le="0.1"} 1` + "\n",
+		`iiko_request_duration_seconds_bucket{endpoint="/api/1/organizations",le="1"} 2` + "\n",
+		`iiko_request_duration_seconds_bucket{endpoint="/api/1/organizations",le="+Inf"} 2` + "\n",
+		`iiko_request_duration_seconds_sum{endpoint="/api/1/organizations"} 0.55` + "\n",
+		`iiko_request_duration_seconds_count{endpoint="/api/1/organizations"} 2` + "\n",
+		`iiko_token_refreshes_total{result="failure"} 1` + "\n",
+		`iiko_token_refreshes_total{result="success"} 1` + "\n",
+		`iiko_unauthorized_retries_total{endpoint="/api/1/organizations"} 1` + "\n",
+		`iiko_webhook_events_total{event_type="DeliveryOrderUpdate",result="success"} 1` + "\n",
+		`iiko_webhook_handler_duration_seconds_bucket{event_type="DeliveryOrderUpdate",le="1"} 0` + "\n",
+		`iiko_schema_drift_total{endpoint="/api/1/organizations",kind="unknown_field",path="a\"b"} 1` + "\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("missing %q in\n%s", want, text)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") || rec.Body.String() != text {
+		t.Errorf("ServeHTTP: content type %q, body differs %v", ct, rec.Body.String() != text)
+	}
+}
+
+func TestPrometheusMetricsConcurrent(t *testing.T) {
+	m := NewPrometheusMetrics()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.ObserveCall("/api/1/organizations", 200, "", time.Millisecond)
+				_ = m.WriteText(&strings.Builder{})
+			}
+		}()
+	}
+	wg.Wait()
+
+	var b strings.Builder
+	_ = m.WriteText(&b)
+	if want := `iiko_requests_total{endpoint="/api/1/organizations",status="200"} 800`; !strings.Contains(b.String(), want) {
+		t.Errorf("missing %q", want)
+	}
+}
+
+func TestClientMetrics(t *testing.T) {
+	var unauthorized atomic.Bool
+	unauthorized.Store(true)
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if unauthorized.CompareAndSwap(true, false) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+	})
+	m := NewPrometheusMetrics()
+	c := newTestClient(t, srv, WithMetrics(m))
+
+	if _, err := c.Organizations(context.Background(), &OrganizationsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	_ = m.WriteText(&b)
+	for _, want := range []string{
+		`iiko_requests_total{endpoint="/api/1/organizations",status="200"} 1`,
+		`iiko_unauthorized_retries_total{endpoint="/api/1/organizations"} 1`,
+		`iiko_token_refreshes_total{result="success"} 2`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("missing %q in\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package iiko
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Call describes one iikoCloud API call as it passes through the middleware chain.
+//
+// Fields above the blank line are set before the chain is invoked; the rest are
+// filled in by the transport once the call has been performed.
+type Call struct {
+	// iiko API endpoint, e.g. "/api/1/organizations".
+	Endpoint string
+	// Whether the call carries the access token.
+	RequiresAuth bool
+	// Marshalled JSON request body.
+	Body []byte
+	// Destination the response is decoded into. A middleware that
+	// short-circuits the call may fill it in itself.
+	Response interface{}
+	// Per-call options. Middleware may append to them before calling next.
+	Options []Option
+
+	// HTTP status code of the last attempt. 0 if no response was received.
+	StatusCode int
+	// Headers and raw body of the last response.
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	// Number of HTTP attempts made, not counting the token refresh.
+	Attempts int
+	// Time spent in the transport, including retries and backoff.
+	Duration time.Duration
+}
+
+// Handler performs a Call. The returned error is the one the API method returns,
+// e.g. *ErrorResponse for iiko API errors.
+type Handler func(ctx context.Context, call *Call) error
+
+// Middleware wraps a Handler to observe or alter API calls: logging, metrics,
+// auditing, fault injection and the like.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware appends middleware to the Client's chain. The first middleware
+// is the outermost one, i.e. it sees the call first and the result last.
+func WithMiddleware(mw ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// handler returns the transport wrapped into the Client's middleware chain.
+func (c *Client) handler() Handler {
+	h := Handler(c.execute)
+	if c.breaker != nil {
+		h = breakCircuits(c.breaker, c.clock)(h)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+	if c.metrics != nil {
+		h = measureCalls(c.metrics)(h)
+	}
+	if c.logger != nil {
+		h = logCalls(c.logger)(h)
+	}
+	if c.tracer != nil {
+		h = traceCalls(c.tracer)(h)
+	}
+	return h
+}
@@ -0,0 +1,158 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+	})
+
+	var mu sync.Mutex
+	var trace []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) error {
+				if call.Endpoint != "/api/1/organizations" {
+					return next(ctx, call)
+				}
+				mu.Lock()
+				trace = append(trace, name+" in")
+				mu.Unlock()
+				err := next(ctx, call)
+				mu.Lock()
+				trace = append(trace, name+" out")
+				mu.Unlock()
+				return err
+			}
+		}
+	}
+	c := newTestClient(t, srv, WithMiddleware(record("a"), record("b")), WithMiddleware(record("c")))
+
+	if _, err := c.Organizations(context.Background(), &OrganizationsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a in", "b in", "c in", "c out", "b out", "a out"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
+
+func TestMiddlewareCall(t *testing.T) {
+	errInjected := errors.New("injected")
+
+	tests := []struct {
+		name      string
+		mw        Middleware
+		wantErr   error
+		wantCalls int
+		check     func(t *testing.T, call *Call)
+	}{
+		{
+			name: "observes the result",
+			mw: func(next Handler) Handler {
+				return next
+			},
+			wantCalls: 1,
+			check: func(t *testing.T, call *Call) {
+				if call.Endpoint != "/api/1/organizations" || !call.RequiresAuth {
+					t.Errorf("call = %s auth %v", call.Endpoint, call.RequiresAuth)
+				}
+				if call.StatusCode != http.StatusOK || call.Attempts != 1 || call.Duration <= 0 {
+					t.Errorf("status %d, attempts %d, duration %v", call.StatusCode, call.Attempts, call.Duration)
+				}
+				if len(call.ResponseBody) == 0 || call.ResponseHeader.Get("Content-Type") == "" {
+					t.Error("response body or header not captured")
+				}
+			},
+		},
+		{
+			name: "short-circuits",
+			mw: func(next Handler) Handler {
+				return func(ctx context.Context, call *Call) error {
+					if call.Endpoint != "/api/1/organizations" {
+						return next(ctx, call)
+					}
+					return errInjected
+				}
+			},
+			wantErr:   errInjected,
+			wantCalls: 0,
+		},
+		{
+			name: "appends options",
+			mw: func(next Handler) Handler {
+				return func(ctx context.Context, call *Call) error {
+					call.Options = append(call.Options, WithCustomTimeout(42*time.Second))
+					return next(ctx, call)
+				}
+			},
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var timeout string
+			srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				timeout = r.Header.Get("Timeout")
+				writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+			})
+
+			var seen *Call
+			observe := func(next Handler) Handler {
+				return func(ctx context.Context, call *Call) error {
+					if call.Endpoint == "/api/1/organizations" {
+						seen = call
+					}
+					return next(ctx, call)
+				}
+			}
+			c := newTestClient(t, srv, WithMiddleware(observe, tt.mw))
+
+			_, err := c.Organizations(context.Background(), &OrganizationsRequest{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := srv.callCount("/api/1/organizations"); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if tt.name == "appends options" && timeout != "42" {
+				t.Errorf("Timeout header = %q, want 42", timeout)
+			}
+			if tt.check != nil {
+				tt.check(t, seen)
+			}
+		})
+	}
+}
+
+func TestMiddlewareFillsResponse(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the server")
+	})
+	stub := func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			if call.Endpoint != "/api/1/organizations" {
+				return next(ctx, call)
+			}
+			call.Response.(*OrganizationsResponse).Organizations = []Organization{{Name: "stub"}}
+			return nil
+		}
+	}
+	c := newTestClient(t, srv, WithMiddleware(stub))
+
+	res, err := c.Organizations(context.Background(), &OrganizationsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Organizations) != 1 || res.Organizations[0].Name != "stub" {
+		t.Errorf("organizations = %+v", res.Organizations)
+	}
+}
@@ -0,0 +1,285 @@
+package iiko
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// moneyMaxScale is the largest number of decimal places a Money keeps; results
+// with more are rounded.
+const moneyMaxScale = 18
+
+// moneyMaxExponent bounds the positive exponent ParseMoney accepts, so that
+// inputs like "1e999999999" are rejected before they are expanded.
+const moneyMaxExponent = 64
+
+// Money is an exact decimal amount of money, used for prices and sums instead
+// of float64. It is encoded in JSON as a number, e.g. 150.5, and decodes
+// numbers and numeric strings exactly, rounded to 18 decimal places.
+//
+// Money values are normalized, so == compares amounts and currencies: 1.50 RUB
+// equals 1.5 RUB. The currency is not part of the JSON encoding; set it with
+// WithCurrency or Organization.RoundMoney. The zero value is 0.
+//
+// Amounts have no size limit and results are rounded to 18 decimal places.
+// Arithmetic panics only if the currencies of the operands differ.
+type Money struct {
+	// decimal is the normalized amount, e.g. "-0.05": no trailing zeros
+	// after the point, and empty for 0.
+	decimal  string
+	currency ISOCurrency
+}
+
+// NewMoney returns units * 10^-scale, e.g. NewMoney(12345, 2) is 123.45.
+func NewMoney(units int64, scale int) Money {
+	return newMoney(big.NewInt(units), scale)
+}
+
+// MoneyFromFloat returns the shortest decimal that converts back to f, e.g.
+// 0.1 for 0.1, not 0.1000000000000000055511151231257827. NaN and infinities
+// are errors.
+func MoneyFromFloat(f float64) (Money, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Money{}, fmt.Errorf("iiko: invalid money %v", f)
+	}
+	return ParseMoney(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// ParseMoney parses a decimal number such as "150", "-0.05" or "1.5e3".
+// Digits beyond 18 decimal places are rounded.
+func ParseMoney(s string) (Money, error) {
+	mantissa, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Money{}, fmt.Errorf("iiko: invalid money %q", s)
+		}
+		if e > moneyMaxExponent {
+			return Money{}, errors.New("iiko: money is out of range")
+		}
+		mantissa, exp = s[:i], e
+		// Any smaller exponent rounds to 0 as well; clamping keeps
+		// len(frac)-exp from overflowing.
+		if minExp := -(len(mantissa) + moneyMaxScale + 1); exp < minExp {
+			exp = minExp
+		}
+	}
+
+	intPart, frac, _ := strings.Cut(mantissa, ".")
+	units, ok := new(big.Int).SetString(intPart+frac, 10)
+	if !ok || strings.ContainsAny(frac, "+-") {
+		return Money{}, fmt.Errorf("iiko: invalid money %q", s)
+	}
+	return newMoney(units, len(frac)-exp), nil
+}
+
+// Currency returns the currency of m, empty if it is not known.
+func (m Money) Currency() ISOCurrency {
+	return m.currency
+}
+
+// WithCurrency returns m in currency.
+func (m Money) WithCurrency(currency ISOCurrency) Money {
+	m.currency = currency
+	return m
+}
+
+// IsZero reports whether m is 0.
+func (m Money) IsZero() bool {
+	return m.decimal == ""
+}
+
+// Sign returns -1, 0 or +1 depending on whether m is negative, zero or positive.
+func (m Money) Sign() int {
+	switch {
+	case m.decimal == "":
+		return 0
+	case m.decimal[0] == '-':
+		return -1
+	default:
+		return 1
+	}
+}
+
+// Cmp returns -1, 0 or +1 depending on whether m is less than, equal to or greater than o.
+func (m Money) Cmp(o Money) int {
+	m.sameCurrency(o)
+	a, b, _ := align(m, o)
+	return a.Cmp(b)
+}
+
+// Add returns m + o.
+func (m Money) Add(o Money) Money {
+	currency := m.sameCurrency(o)
+	a, b, scale := align(m, o)
+	return newMoney(a.Add(a, b), scale).WithCurrency(currency)
+}
+
+// Sub returns m - o.
+func (m Money) Sub(o Money) Money {
+	return m.Add(o.Neg())
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	units, scale := m.units()
+	return newMoney(units.Neg(units), scale).WithCurrency(m.currency)
+}
+
+// MulAmount returns m multiplied by amount, e.g. the sum of an order item
+// from its price and amount. The amount is taken as its shortest decimal
+// representation, see MoneyFromFloat; a NaN or infinite amount gives 0.
+func (m Money) MulAmount(amount float64) Money {
+	q, err := MoneyFromFloat(amount)
+	if err != nil {
+		return Money{currency: m.currency}
+	}
+	a, scaleA := m.units()
+	b, scaleB := q.units()
+	return newMoney(a.Mul(a, b), scaleA+scaleB).WithCurrency(m.currency)
+}
+
+// Round rounds m half away from zero to a multiple of denomination, e.g. to
+// Organization.CurrencyMinimumDenomination. A non-positive denomination leaves m as is.
+func (m Money) Round(denomination Money) Money {
+	if denomination.Sign() <= 0 {
+		return m
+	}
+
+	a, d, scale := align(m, denomination)
+	q := quoRound(a, d)
+	return newMoney(q.Mul(q, d), scale).WithCurrency(m.currency)
+}
+
+// Float64 returns the nearest float64 to m.
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.String(), 64)
+	return f
+}
+
+// String returns m as a decimal number without its currency, e.g. "-0.05".
+func (m Money) String() string {
+	if m.decimal == "" {
+		return "0"
+	}
+	return m.decimal
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts numbers, numeric
+// strings and null, which decodes as 0.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) || bytes.Equal(data, []byte(`""`)) {
+		*m = Money{}
+		return nil
+	}
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+
+	parsed, err := ParseMoney(string(data))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// RoundMoney rounds m to the minimum denomination of the organization's
+// currency and returns it in that currency.
+func (o *Organization) RoundMoney(m Money) Money {
+	return m.Round(o.CurrencyMinimumDenomination).WithCurrency(o.CurrencyIsoName)
+}
+
+// sameCurrency returns the currency of an operation on m and o. It panics if
+// both currencies are known and differ.
+func (m Money) sameCurrency(o Money) ISOCurrency {
+	switch {
+	case m.currency == "":
+		return o.currency
+	case o.currency == "" || o.currency == m.currency:
+		return m.currency
+	default:
+		panic(fmt.Sprintf("iiko: money in %s and %s cannot be combined", m.currency, o.currency))
+	}
+}
+
+// units returns m as units * 10^-scale.
+func (m Money) units() (units *big.Int, scale int) {
+	intPart, frac, _ := strings.Cut(m.decimal, ".")
+	units, _ = new(big.Int).SetString(intPart+frac, 10)
+	if units == nil {
+		units = new(big.Int)
+	}
+	return units, len(frac)
+}
+
+// align returns the units of m and o at a common scale.
+func align(m, o Money) (a, b *big.Int, scale int) {
+	a, scaleA := m.units()
+	b, scaleB := o.units()
+	scale = max(scaleA, scaleB)
+	a.Mul(a, pow10(scale-scaleA))
+	b.Mul(b, pow10(scale-scaleB))
+	return a, b, scale
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// quoRound returns a / d rounded half away from zero. d must be positive.
+func quoRound(a, d *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(a, d, new(big.Int))
+	if r.Abs(r).Lsh(r, 1).Cmp(d) >= 0 {
+		q.Add(q, big.NewInt(int64(a.Sign())))
+	}
+	return q
+}
+
+// newMoney returns units * 10^-scale normalized, rounded half away from zero
+// to moneyMaxScale decimal places. units is modified.
+func newMoney(units *big.Int, scale int) Money {
+	if scale < 0 {
+		units.Mul(units, pow10(-scale))
+		scale = 0
+	}
+	if scale > moneyMaxScale {
+		// Below half of the last kept place the amount rounds to 0; tell it
+		// from the digit count instead of computing a huge power of ten.
+		if scale-moneyMaxScale > len(units.Text(10)) {
+			return Money{}
+		}
+		units, scale = quoRound(units, pow10(scale-moneyMaxScale)), moneyMaxScale
+	}
+
+	digits := units.Text(10)
+	if digits == "0" {
+		return Money{}
+	}
+	sign := ""
+	if digits[0] == '-' {
+		sign, digits = "-", digits[1:]
+	}
+	for scale > 0 && digits[len(digits)-1] == '0' {
+		digits, scale = digits[:len(digits)-1], scale-1
+	}
+	if scale == 0 {
+		return Money{decimal: sign + digits}
+	}
+
+	if pad := scale + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - scale
+	return Money{decimal: sign + digits[:point] + "." + digits[point:]}
+}
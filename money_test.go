@@ -0,0 +1,296 @@
+package iiko
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func mustParseMoney(t *testing.T, s string) Money {
+	t.Helper()
+
+	m, err := ParseMoney(s)
+	if err != nil {
+		t.Fatalf("ParseMoney(%q): %v", s, err)
+	}
+	return m
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "150", want: "150"},
+		{in: "-0.05", want: "-0.05"},
+		{in: "1.50", want: "1.5"},
+		{in: "1.5e3", want: "1500"},
+		{in: "15E-1", want: "1.5"},
+		{in: "0.000", want: "0"},
+		{in: "-0", want: "0"},
+		{in: "99999999999999999999", want: "99999999999999999999"},
+		{in: "123456789012345678901234567890.5", want: "123456789012345678901234567890.5"},
+		{in: "0.000000000000000001", want: "0.000000000000000001"},
+		{in: "0.0000000000000000015", want: "0.000000000000000002"},
+		{in: "-0.0000000000000000015", want: "-0.000000000000000002"},
+		{in: "0.0000000000000000004", want: "0"},
+		{in: "1e-70", want: "0"},
+		{in: "1e-999999999", want: "0"},
+		{in: "1e-9223372036854775808", want: "0"},
+		{in: "-123.45e-9223372036854775808", want: "0"},
+		{in: "5e-19", want: "0.000000000000000001"},
+		{in: "1e64", want: "1" + strings.Repeat("0", 64)},
+		{in: "1e65", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1.-5", wantErr: true},
+		{in: "1e", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMoney(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseMoney(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  func() Money
+		want string
+	}{
+		{"add", func() Money { return NewMoney(150, 2).Add(NewMoney(25, 1)) }, "4"},
+		{"add tiny to large", func() Money { return mustParseMoney(t, "0.000000000000000001").Add(NewMoney(10, 0)) }, "10.000000000000000001"},
+		{"add beyond int64", func() Money { return mustParseMoney(t, "9223372036854775807").Add(NewMoney(1, 0)) }, "9223372036854775808"},
+		{"sub", func() Money { return NewMoney(1, 0).Sub(NewMoney(5, 2)) }, "0.95"},
+		{"neg", func() Money { return NewMoney(-5, 2).Neg() }, "0.05"},
+		{"mul amount", func() Money { return NewMoney(12345, 2).MulAmount(2.5) }, "308.625"},
+		{"mul third", func() Money { return NewMoney(12345, 2).MulAmount(1.0 / 3) }, "41.149999999999995885"},
+		{"mul tiny", func() Money { return mustParseMoney(t, "0.000000000000000001").MulAmount(0.1) }, "0"},
+		{"mul NaN", func() Money { return NewMoney(1, 0).MulAmount(math.NaN()) }, "0"},
+		{"round", func() Money { return NewMoney(12345, 3).Round(NewMoney(1, 2)) }, "12.35"},
+		{"round negative", func() Money { return NewMoney(-12345, 3).Round(NewMoney(1, 2)) }, "-12.35"},
+		{"round to 50", func() Money { return NewMoney(174, 0).Round(NewMoney(50, 0)) }, "150"},
+		{"round no denomination", func() Money { return NewMoney(12345, 3).Round(Money{}) }, "12.345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got(); got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyCompare(t *testing.T) {
+	tests := []struct {
+		a, b     Money
+		wantCmp  int
+		wantSame bool
+	}{
+		{NewMoney(150, 2), mustParseMoney(t, "1.5"), 0, true},
+		{NewMoney(15, 1).WithCurrency("RUB"), mustParseMoney(t, "1.500").WithCurrency("RUB"), 0, true},
+		{NewMoney(15, 1).WithCurrency("RUB"), NewMoney(15, 1), 0, false},
+		{NewMoney(-1, 0), Money{}, -1, false},
+		{mustParseMoney(t, "99999999999999999999"), NewMoney(math.MaxInt64, 0), 1, false},
+		{Money{}, NewMoney(0, 5), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a.String()+" "+tt.b.String(), func(t *testing.T) {
+			if got := tt.a.Cmp(tt.b); got != tt.wantCmp {
+				t.Errorf("Cmp = %d, want %d", got, tt.wantCmp)
+			}
+			if got := tt.a == tt.b; got != tt.wantSame {
+				t.Errorf("== is %v, want %v", got, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestMoneyCurrencyMismatchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("adding RUB to USD did not panic")
+		}
+	}()
+	NewMoney(1, 0).WithCurrency("RUB").Add(NewMoney(1, 0).WithCurrency("USD"))
+}
+
+func TestMoneyFromFloat(t *testing.T) {
+	tests := []struct {
+		in      float64
+		want    string
+		wantErr bool
+	}{
+		{in: 0.1, want: "0.1"},
+		{in: 150.5, want: "150.5"},
+		{in: 1e20, want: "100000000000000000000"},
+		{in: 1e-20, want: "0"},
+		{in: math.NaN(), wantErr: true},
+		{in: math.Inf(1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := MoneyFromFloat(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("MoneyFromFloat(%v): err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("MoneyFromFloat(%v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		json     string
+		want     string
+		wantJSON string
+		wantErr  bool
+	}{
+		{json: `150.5`, want: "150.5", wantJSON: `150.5`},
+		{json: `"150.50"`, want: "150.5", wantJSON: `150.5`},
+		{json: `null`, want: "0", wantJSON: `0`},
+		{json: `""`, want: "0", wantJSON: `0`},
+		{json: `1e-70`, want: "0", wantJSON: `0`},
+		{json: `99999999999999999999`, want: "99999999999999999999", wantJSON: `99999999999999999999`},
+		{json: `-0.000001`, want: "-0.000001", wantJSON: `-0.000001`},
+		{json: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var got Money
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantJSON {
+				t.Errorf("Marshal = %s, want %s", data, tt.wantJSON)
+			}
+		})
+	}
+}
+
+func TestMoneyFields(t *testing.T) {
+	var combos ComboGetCombosInfoResponse
+	err := json.Unmarshal([]byte(`{"comboSpecifications":[{"priceModification":12.5,"groups":[{"products":[{"priceModificationAmount":-0.99}]}]}]}`), &combos)
+	if err != nil {
+		t.Fatalf("combos: %v", err)
+	}
+	spec := combos.ComboSpecifications[0]
+	if want := NewMoney(125, 1); spec.PriceModification != want {
+		t.Errorf("PriceModification = %s, want %s", spec.PriceModification, want)
+	}
+	if want := NewMoney(-99, 2); spec.Groups[0].Products[0].PriceModificationAmount != want {
+		t.Errorf("PriceModificationAmount = %s, want %s", spec.Groups[0].Products[0].PriceModificationAmount, want)
+	}
+
+	var wallet WalletBalance
+	if err := json.Unmarshal([]byte(`{"balance":1234.56}`), &wallet); err != nil {
+		t.Fatalf("wallet: %v", err)
+	}
+	if want := NewMoney(123456, 2); wallet.Balance != want {
+		t.Errorf("Balance = %s, want %s", wallet.Balance, want)
+	}
+}
+
+func TestOrganizationRoundMoney(t *testing.T) {
+	org := &Organization{CurrencyIsoName: "RUB", CurrencyMinimumDenomination: NewMoney(1, 2)}
+
+	got := org.RoundMoney(NewMoney(12345, 1).MulAmount(1.0 / 3))
+	if want := NewMoney(4115, 1).WithCurrency("RUB"); got != want {
+		t.Errorf("RoundMoney = %s %s, want %s %s", got, got.Currency(), want, want.Currency())
+	}
+}
+
+func TestOrderItemPricesJSON(t *testing.T) {
+	req := OrderCreateRequest{Order: Order{Items: []Item{{
+		Type:   OrderItemCompound,
+		Amount: 1,
+		PrimaryComponent: Component{
+			Price:     NewMoney(1505, 1),
+			Modifiers: []Modifier{{Amount: 1, Price: NewMoney(25, 0)}},
+		},
+		SecondaryComponent: &Component{Price: NewMoney(99, 2)},
+		CommonModifiers:    []Modifier{{Amount: 2, Price: NewMoney(5, 1)}},
+	}}}}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Order struct {
+			Items []struct {
+				PrimaryComponent struct {
+					Price     json.RawMessage `json:"price"`
+					Modifiers []struct {
+						Price json.RawMessage `json:"price"`
+					} `json:"modifiers"`
+				} `json:"primaryComponent"`
+				SecondaryComponent struct {
+					Price json.RawMessage `json:"price"`
+				} `json:"secondaryComponent"`
+				CommonModifiers []struct {
+					Price json.RawMessage `json:"price"`
+				} `json:"commonModifiers"`
+			} `json:"items"`
+		} `json:"order"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	item := decoded.Order.Items[0]
+
+	tests := []struct {
+		name string
+		got  json.RawMessage
+		want string
+	}{
+		{"component", item.PrimaryComponent.Price, "150.5"},
+		{"component modifier", item.PrimaryComponent.Modifiers[0].Price, "25"},
+		{"secondary component", item.SecondaryComponent.Price, "0.99"},
+		{"common modifier", item.CommonModifiers[0].Price, "0.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if string(tt.got) != tt.want {
+				t.Errorf("price = %s, want %s in %s", tt.got, tt.want, data)
+			}
+		})
+	}
+	if strings.Contains(string(data), `"float64"`) {
+		t.Errorf("prices are sent under \"float64\": %s", data)
+	}
+
+	var back OrderCreateRequest
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if got := back.Order.Items[0].PrimaryComponent.Modifiers[0].Price; got != NewMoney(25, 0) {
+		t.Errorf("decoded modifier price = %s, want 25", got)
+	}
+}
@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -40,9 +42,9 @@ type ProductCategory struct {
 }
 
 type Price struct {
-	CurrentPrice       int    `json:"currentPrice"`
+	CurrentPrice       Money  `json:"currentPrice"`
 	IsIncludedInMenu   bool   `json:"isIncludedInMenu"`
-	NextPrice          int    `json:"nextPrice"`
+	NextPrice          Money  `json:"nextPrice"`
 	NextIncludedInMenu bool   `json:"nextIncludedInMenu"`
 	NextDatePrice      string `json:"nextDatePrice"`
 }
@@ -155,15 +157,11 @@ type NomenclatureResponse struct {
 	Revision int64 `json:"revision"`
 }
 
+var nomenclatureEndpoint = NewEndpoint[NomenclatureRequest, NomenclatureResponse](EndpointInfo{Path: "/api/1/nomenclature", RequiresAuth: true, Idempotent: true})
+
 // Menu.
 //
 // iiko API: /api/1/nomenclature
-func (c *Client) Nomenclature(req *NomenclatureRequest, opts ...Option) (*NomenclatureResponse, error) {
-	var response NomenclatureResponse
-
-	if err := c.post(true, "/api/1/nomenclature", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) Nomenclature(ctx context.Context, req *NomenclatureRequest, opts ...Option) (*NomenclatureResponse, error) {
+	return nomenclatureEndpoint.Call(ctx, c, req, opts...)
 }
@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -26,15 +28,11 @@ type NotificationsSendResponse struct {
 	CorrelationID uuid.UUID `json:"correlationId"`
 }
 
+var notificationsSendEndpoint = NewEndpoint[NotificationsSendRequest, NotificationsSendResponse](EndpointInfo{Path: "/api/1/notifications/send", RequiresAuth: true})
+
 // Send notification to external systems (iikoFront and iikoWeb).
 //
 // iiko API: /api/1/notifications/send
-func (c *Client) NotificationsSend(req *NotificationsSendRequest, opts ...Option) (*NotificationsSendResponse, error) {
-	var response NotificationsSendResponse
-
-	if err := c.post(true, "/api/1/notifications/send", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) NotificationsSend(ctx context.Context, req *NotificationsSendRequest, opts ...Option) (*NotificationsSendResponse, error) {
+	return notificationsSendEndpoint.Call(ctx, c, req, opts...)
 }
@@ -1,7 +1,7 @@
 package iiko
 
 import (
-	"time"
+	"context"
 
 	"github.com/google/uuid"
 )
@@ -172,7 +172,7 @@ type Customer struct {
 	// Comment.
 	Comment string `json:"comment,omitempty"`
 	// Date of birth.
-	Birthdate time.Time `json:"birthdate,omitzero"`
+	Birthdate Time `json:"birthdate,omitzero"`
 	// Email.
 	Email string `json:"email,omitempty"`
 	// Whether customer receives order status notification messages.
@@ -208,7 +208,7 @@ type Payment struct {
 	PaymentTypeID   uuid.UUID       `json:"paymentTypeId"`
 	PaymentTypeKind PaymentTypeKind `json:"paymentTypeKind"`
 	// Amount due.
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 	// Additional payment parameters.
 	PaymentAdditionalData PaymentAdditionalData `json:"paymentAdditionalData"`
 	// Whether payment item is processed by external payment system (made from outside).
@@ -242,7 +242,7 @@ type Combo struct {
 	// Quantity.
 	Amount int `json:"amount"`
 	// Price of one combo.
-	Price float64 `json:"price"`
+	Price Money `json:"price"`
 	// Combo validity ID.
 	SourceId uuid.UUID `json:"sourceid"`
 	// Combo validity ID.
@@ -288,7 +288,7 @@ type ComboInformation struct {
 
 type Component struct {
 	ProductID  uuid.UUID  `json:"productId"`
-	Price      float64    `json:"float64"`
+	Price      Money      `json:"price"`
 	PositionID uuid.UUID  `json:"positionId"`
 	Modifiers  []Modifier `json:"modifiers"`
 }
@@ -299,7 +299,7 @@ type Modifier struct {
 	// Modifiers group ID (for group modifier). Required for a group modifier.
 	// Can be obtained by /api/1/nomenclature operation.
 	ProductGroupID uuid.UUID `json:"productGroupId"`
-	Price          float64   `json:"float64"`
+	Price          Money     `json:"price"`
 	PositionID     uuid.UUID `json:"positionId"`
 }
 
@@ -312,7 +312,7 @@ type Tip struct {
 	PaymentTypeID   uuid.UUID       `json:"paymentTypeId"`
 	PaymentTypeKind PaymentTypeKind `json:"paymentTypeKind"`
 	// Amount due.
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 	// Additional payment parameters.
 	PaymentAdditionalData PaymentAdditionalData `json:"paymentAdditionalData"`
 	// Whether payment item is processed by external payment system (made from outside).
@@ -346,7 +346,7 @@ type Order struct {
 	// Order status.
 	OrderStatus OrderStatus `json:"status"`
 	// Order creation date (terminal time zone).
-	WhenCreated time.Time `json:"whenCreated"`
+	WhenCreated Time `json:"whenCreated"`
 	// Order waiter.
 	Waiter Waiter `json:"waiter"`
 	// Guests information.
@@ -365,9 +365,9 @@ type Order struct {
 	// Needed to limit the visibility of orders for external integration.
 	SourceKey string `json:"sourceKey"`
 	// Invoice printing time (guest bill time).
-	WhenBillPrinted time.Time `json:"whenBillPrinted"`
+	WhenBillPrinted Time `json:"whenBillPrinted"`
 	// Delivery closing time (Local for delivery terminal).
-	WhenClosed time.Time `json:"whenClosed"`
+	WhenClosed Time `json:"whenClosed"`
 	// Concept.
 	Conception Conception `json:"conception"`
 	// Discounts/surcharges.
@@ -413,7 +413,7 @@ type OrderDiscount struct {
 	// Can be obtained by /api/1/discounts operation.
 	DiscountTypeID uuid.UUID `json:"discountTypeId"`
 	// Discount/surcharge sum.
-	Sum float64 `json:"sum"`
+	Sum Money `json:"sum"`
 	// Order item positions.
 	SelectivePositions []uuid.UUID       `json:"selectivePositions"`
 	Type               OrderDiscountType `json:"type"`
@@ -426,15 +426,11 @@ type DiscountInfo struct {
 	Discounts []OrderDiscount `json:"discounts"`
 }
 
+var orderCreateEndpoint = NewEndpoint[OrderCreateRequest, OrderCreateResponse](EndpointInfo{Path: "/api/1/order/create", RequiresAuth: true})
+
 // OrderCreate Create a new order
 //
 // iiko API: /api/1/order/create
-func (c *Client) OrderCreate(req *OrderCreateRequest, opts ...Option) (*OrderCreateResponse, error) {
-	var response OrderCreateResponse
-
-	if err := c.post(true, "/api/1/order/create", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) OrderCreate(ctx context.Context, req *OrderCreateRequest, opts ...Option) (*OrderCreateResponse, error) {
+	return orderCreateEndpoint.Call(ctx, c, req, opts...)
 }
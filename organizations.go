@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -50,7 +52,7 @@ type Organization struct {
 	CurrencyIsoName ISOCurrency `json:"currencyIsoName"`
 
 	// Value rounding of position. [required]
-	CurrencyMinimumDenomination float64 `json:"currencyMinimumDenomination"`
+	CurrencyMinimumDenomination Money `json:"currencyMinimumDenomination"`
 
 	// Country dialing code. [required]
 	CountryPhoneCode string `json:"countryPhoneCode"`
@@ -108,15 +110,11 @@ type OrganizationsResponse struct {
 	Organizations []Organization `json:"organizations"`
 }
 
+var organizationsEndpoint = NewEndpoint[OrganizationsRequest, OrganizationsResponse](EndpointInfo{Path: "/api/1/organizations", RequiresAuth: true, Idempotent: true})
+
 // Returns organizations available to api-login user.
 //
 // iiko API: /api/1/organizations
-func (c *Client) Organizations(req *OrganizationsRequest, opts ...Option) (*OrganizationsResponse, error) {
-	var response OrganizationsResponse
-
-	if err := c.post(true, "/api/1/organizations", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) Organizations(ctx context.Context, req *OrganizationsRequest, opts ...Option) (*OrganizationsResponse, error) {
+	return organizationsEndpoint.Call(ctx, c, req, opts...)
 }
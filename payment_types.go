@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -77,15 +79,11 @@ type PaymentTypesResponse struct {
 	PaymentTypes []PaymentType `json:"paymentTypes"`
 }
 
+var paymentTypesEndpoint = NewEndpoint[PaymentTypesRequest, PaymentTypesResponse](EndpointInfo{Path: "/api/1/payment_types", RequiresAuth: true, Idempotent: true})
+
 // Payment types.
 //
 // iiko API: /api/1/payment_types
-func (c *Client) PaymentTypes(req *PaymentTypesRequest, opts ...Option) (*PaymentTypesResponse, error) {
-	var response PaymentTypesResponse
-
-	if err := c.post(true, "/api/1/payment_types", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) PaymentTypes(ctx context.Context, req *PaymentTypesRequest, opts ...Option) (*PaymentTypesResponse, error) {
+	return paymentTypesEndpoint.Call(ctx, c, req, opts...)
 }
@@ -0,0 +1,140 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitWait is returned when the client-side rate limiter can not let a
+// request through before the deadline of its context.
+var ErrRateLimitWait = errors.New("iiko: rate limit wait exceeds context deadline")
+
+// EndpointClass groups iikoCloud methods that share one rate limit budget.
+type EndpointClass string
+
+const (
+	ReadEndpoint     EndpointClass = "read"
+	MutationEndpoint EndpointClass = "mutation"
+	TokenEndpoint    EndpointClass = "token"
+)
+
+// ClassifyEndpoint returns the class the endpoint belongs to.
+func ClassifyEndpoint(endpoint string) EndpointClass {
+	switch {
+	case endpoint == accessTokenEndpoint.Info().Path:
+		return TokenEndpoint
+	case isMutation(endpoint):
+		return MutationEndpoint
+	default:
+		return ReadEndpoint
+	}
+}
+
+// RateLimit is a token bucket budget: Rate requests per second on average
+// with bursts of up to Burst requests. A zero Rate means no limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimits holds separate budgets for every endpoint class.
+type RateLimits struct {
+	Reads     RateLimit
+	Mutations RateLimit
+	Token     RateLimit
+}
+
+// WithRateLimits makes the Client throttle itself before iikoCloud does.
+// Requests over budget wait for their turn, but never past their context deadline.
+func WithRateLimits(l RateLimits) ClientOption {
+	return func(c *Client) {
+		c.limiters = map[EndpointClass]*tokenBucket{
+			ReadEndpoint:     newTokenBucket(l.Reads),
+			MutationEndpoint: newTokenBucket(l.Mutations),
+			TokenEndpoint:    newTokenBucket(l.Token),
+		}
+	}
+}
+
+// waitRateLimit blocks until a request to endpoint fits into its budget.
+func (c *Client) waitRateLimit(ctx context.Context, endpoint string) error {
+	if c.limiters == nil {
+		return nil
+	}
+	return c.limiters[ClassifyEndpoint(endpoint)].wait(ctx)
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns nil for an unlimited budget.
+func newTokenBucket(l RateLimit) *tokenBucket {
+	if l.Rate <= 0 {
+		return nil
+	}
+
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   l.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// wait takes one token, sleeping until it is available. Waiting callers queue
+// in FIFO order because every caller reserves its token before sleeping.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens--
+
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		b.cancel()
+		return ErrRateLimitWait
+	}
+
+	if err := sleepContext(ctx, delay); err != nil {
+		b.cancel()
+		return err
+	}
+
+	return nil
+}
+
+// cancel returns a reserved but unused token to the bucket.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	b.tokens++
+	b.mu.Unlock()
+}
@@ -0,0 +1,148 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClassifyEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     EndpointClass
+	}{
+		{"/api/1/access_token", TokenEndpoint},
+		{"/api/1/organizations", ReadEndpoint},
+		{"/api/1/deliveries/create", MutationEndpoint},
+		{"/api/1/deliveries/update_order_delivery_status", MutationEndpoint},
+		{"/api/1/loyalty/iiko/customer/info", ReadEndpoint},
+		{"/api/1/unregistered", ReadEndpoint},
+	}
+	for _, tt := range tests {
+		if got := ClassifyEndpoint(tt.endpoint); got != tt.want {
+			t.Errorf("ClassifyEndpoint(%q) = %s, want %s", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     RateLimit
+		calls     int
+		minTotal  time.Duration
+		maxTotal  time.Duration
+		unlimited bool
+	}{
+		{"unlimited", RateLimit{}, 100, 0, 50 * time.Millisecond, true},
+		{"within burst", RateLimit{Rate: 10, Burst: 5}, 5, 0, 50 * time.Millisecond, false},
+		{"over burst waits", RateLimit{Rate: 20, Burst: 2}, 4, 90 * time.Millisecond, time.Second, false},
+		{"burst below one", RateLimit{Rate: 50, Burst: 0}, 2, 15 * time.Millisecond, time.Second, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTokenBucket(tt.limit)
+			if (b == nil) != tt.unlimited {
+				t.Fatalf("bucket = %v, unlimited %v", b, tt.unlimited)
+			}
+			start := time.Now()
+			for i := 0; i < tt.calls; i++ {
+				if err := b.wait(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if d := time.Since(start); d < tt.minTotal || d > tt.maxTotal {
+				t.Errorf("took %v, want within [%v, %v]", d, tt.minTotal, tt.maxTotal)
+			}
+		})
+	}
+}
+
+func TestTokenBucketDeadline(t *testing.T) {
+	b := newTokenBucket(RateLimit{Rate: 1, Burst: 1})
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.wait(ctx); !errors.Is(err, ErrRateLimitWait) {
+		t.Fatalf("err = %v, want ErrRateLimitWait", err)
+	}
+
+	// The refused call must have returned its reservation.
+	b.mu.Lock()
+	tokens := b.tokens
+	b.mu.Unlock()
+	if tokens < -0.01 {
+		t.Errorf("tokens = %v, reservation not returned", tokens)
+	}
+}
+
+func TestTokenBucketCancel(t *testing.T) {
+	b := newTokenBucket(RateLimit{Rate: 1, Burst: 1})
+	_ = b.wait(context.Background())
+
+	tokens := func() float64 {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.tokens
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- b.wait(ctx) }()
+
+	waitFor(t, "the reservation", func() bool { return tokens() < 0 })
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if got := tokens(); got < -0.01 {
+		t.Errorf("tokens = %v, reservation not returned", got)
+	}
+}
+
+func TestTokenBucketConcurrent(t *testing.T) {
+	b := newTokenBucket(RateLimit{Rate: 200, Burst: 10})
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 20 calls over the burst at 200/s take at least 100ms.
+	if d := time.Since(start); d < 90*time.Millisecond {
+		t.Errorf("30 calls took %v, want at least 100ms", d)
+	}
+}
+
+func TestClientRateLimits(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+	})
+	c := newTestClient(t, srv, WithRateLimits(RateLimits{Reads: RateLimit{Rate: 1, Burst: 1}}))
+
+	if _, err := c.Organizations(context.Background(), &OrganizationsRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Organizations(ctx, &OrganizationsRequest{}); !errors.Is(err, ErrRateLimitWait) {
+		t.Fatalf("err = %v, want ErrRateLimitWait", err)
+	}
+	if got := srv.callCount("/api/1/organizations"); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
@@ -0,0 +1,130 @@
+package iiko
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// Redacted replaces secret and personal values in logs and captures.
+const Redacted = "[REDACTED]"
+
+// RedactedTime replaces dates of birth, so that redacted bodies still decode
+// into Time.
+const RedactedTime = "1900-01-01 00:00:00.000"
+
+// secretKeys are JSON keys whose values are always redacted: credentials,
+// tokens and customer contacts.
+var secretKeys = map[string]bool{
+	"apiLogin":     true,
+	"clientSecret": true,
+	"token":        true,
+	"authToken":    true,
+	"phone":        true,
+	"email":        true,
+	"cardTrack":    true,
+	"cardNumber":   true,
+	"track":        true,
+	"credential":   true,
+}
+
+// timeKeys are JSON keys of personal dates, redacted to RedactedTime.
+var timeKeys = map[string]bool{
+	"birthday":  true,
+	"birthdate": true,
+}
+
+// personalKeys are JSON keys that hold personal data only inside a customer
+// object; elsewhere "name" is a product, a menu or a city.
+var personalKeys = map[string]bool{
+	"name":       true,
+	"surname":    true,
+	"surName":    true,
+	"middleName": true,
+}
+
+// cardKeys are JSON keys that hold loyalty card data only inside a card
+// object; elsewhere "number" is e.g. an order number.
+var cardKeys = map[string]bool{
+	"number": true,
+}
+
+// cardObjects are JSON keys whose values describe loyalty cards.
+var cardObjects = map[string]bool{
+	"card":  true,
+	"cards": true,
+}
+
+// customerEndpoints are endpoints whose top-level object describes a customer.
+var customerEndpoints = map[string]bool{
+	"/api/1/loyalty/iiko/customer/info":             true,
+	"/api/1/loyalty/iiko/customer/create_or_update": true,
+}
+
+// RedactBody returns a copy of the JSON body of a request to or a response from
+// endpoint with secrets, customer personal data and loyalty cards replaced by
+// Redacted, and dates of birth by RedactedTime. Bodies that are not valid JSON
+// cannot be scrubbed and are replaced by Redacted as a whole.
+func RedactBody(endpoint string, body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+
+	// UseNumber keeps numbers byte-exact instead of rounding them through float64.
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return []byte(Redacted)
+	}
+
+	redacted, err := json.Marshal(redactValue(v, redactScope{personal: customerEndpoints[endpoint]}))
+	if err != nil {
+		return []byte(Redacted)
+	}
+
+	return redacted
+}
+
+// RedactHeader returns a copy of h with the access token hidden.
+func RedactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	if h.Get("Authorization") != "" {
+		h.Set("Authorization", "Bearer "+Redacted)
+	}
+	return h
+}
+
+// redactScope tells which context-dependent keys are redacted in a JSON value.
+type redactScope struct {
+	// personal is set inside a customer object.
+	personal bool
+	// card is set inside a loyalty card object.
+	card bool
+}
+
+func redactValue(v interface{}, scope redactScope) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			switch {
+			case child == nil:
+			case timeKeys[k]:
+				v[k] = RedactedTime
+			case secretKeys[k], scope.personal && personalKeys[k], scope.card && cardKeys[k]:
+				v[k] = Redacted
+			default:
+				v[k] = redactValue(child, redactScope{
+					personal: scope.personal || k == "customer",
+					card:     cardObjects[k],
+				})
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(child, scope)
+		}
+	}
+	return v
+}
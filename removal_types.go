@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -47,15 +49,11 @@ type RemovalTypesResponse struct {
 	RemovalTypes []RemovalType `json:"paymentTypes"`
 }
 
+var removalTypesEndpoint = NewEndpoint[RemovalTypesRequest, RemovalTypesResponse](EndpointInfo{Path: "/api/1/removal_types", RequiresAuth: true, Idempotent: true})
+
 // Removal types (reasons for deletion). Allowed from version 7.5.3.
 //
 // iiko API: /api/1/removal_types
-func (c *Client) RemovalTypes(req *RemovalTypesRequest, opts ...Option) (*RemovalTypesResponse, error) {
-	var response RemovalTypesResponse
-
-	if err := c.post(true, "/api/1/removal_types", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) RemovalTypes(ctx context.Context, req *RemovalTypesRequest, opts ...Option) (*RemovalTypesResponse, error) {
+	return removalTypesEndpoint.Call(ctx, c, req, opts...)
 }
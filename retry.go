@@ -0,0 +1,185 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how doRequest retries transient iikoCloud failures:
+// network errors, 429 Too Many Requests and 5xx responses.
+//
+// Only requests to endpoints registered as idempotent (see EndpointInfo) are
+// replayed. Mutations and endpoints missing from the registry, e.g. ones called
+// through Post, are sent once unless IsRetryable allows them or the caller
+// marks the call as safe with WithRetrySafe, e.g. because the order carries a
+// client-generated ID that iiko deduplicates on.
+type RetryPolicy struct {
+	// Total number of attempts, including the first one. Values below 2 disable retries.
+	MaxAttempts int
+
+	// Delay before the first retry. It doubles on every next attempt.
+	InitialBackoff time.Duration
+
+	// Upper bound for the backoff. Zero means no bound.
+	// A Retry-After header sent by iiko takes precedence over the computed
+	// backoff, but is capped by MaxBackoff too.
+	MaxBackoff time.Duration
+
+	// Fraction in [0, 1] of the backoff that is randomized to spread retries of
+	// concurrent callers. 0 disables jitter.
+	Jitter float64
+
+	// IsRetryable reports whether requests to endpoint may be retried.
+	// If nil, only endpoints registered as idempotent are retryable.
+	IsRetryable func(endpoint string) bool
+}
+
+// DefaultRetryPolicy is a reasonable policy for production use.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Jitter:         0.2,
+}
+
+// WithRetryPolicy enables retries of transient failures for all requests of the Client.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = &p
+	}
+}
+
+type withRetrySafe struct{}
+
+func (withRetrySafe) Apply(*http.Request) {}
+
+// WithRetrySafe marks one API request as safe to replay even if its endpoint
+// is a mutation, e.g. /deliveries/create with a client-generated order ID.
+func WithRetrySafe() Option {
+	return withRetrySafe{}
+}
+
+// attempts returns how many times a request to endpoint may be sent.
+func (p *RetryPolicy) attempts(endpoint string, opts []Option) int {
+	if p == nil || p.MaxAttempts < 2 {
+		return 1
+	}
+
+	for _, opt := range opts {
+		if _, ok := opt.(withRetrySafe); ok {
+			return p.MaxAttempts
+		}
+	}
+
+	retryable := isIdempotent(endpoint)
+	if p.IsRetryable != nil {
+		retryable = p.IsRetryable(endpoint)
+	}
+	if !retryable {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+// backoff returns the delay before the retry that follows the given attempt (1-based).
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxBackoff > 0 && d > p.MaxBackoff {
+				d = p.MaxBackoff
+			}
+			return d
+		}
+	}
+
+	d := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+
+	return d
+}
+
+// parseRetryAfter parses the Retry-After header in both of its forms:
+// delay in seconds and HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// isTransient reports whether the outcome of one attempt is worth retrying.
+func isTransient(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// The caller gave up; retrying would only hide that.
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+			errors.Is(err, ErrRateLimitWait) {
+			return false
+		}
+		// A failed token refresh surfaces as an API error of its own.
+		var apiErr *ErrorResponse
+		if errors.As(err, &apiErr) {
+			return isTransientStatus(apiErr.StatusCode)
+		}
+		return true
+	}
+
+	return isTransientStatus(resp.StatusCode)
+}
+
+func isTransientStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// discardResponse drains and closes the body so the connection can be reused.
+func discardResponse(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
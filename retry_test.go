@@ -0,0 +1,165 @@
+package iiko
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyAttempts(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3}
+	allowAll := &RetryPolicy{MaxAttempts: 3, IsRetryable: func(string) bool { return true }}
+	denyAll := &RetryPolicy{MaxAttempts: 3, IsRetryable: func(string) bool { return false }}
+
+	tests := []struct {
+		name     string
+		policy   *RetryPolicy
+		endpoint string
+		opts     []Option
+		want     int
+	}{
+		{"nil policy", nil, "/api/1/organizations", nil, 1},
+		{"single attempt", &RetryPolicy{MaxAttempts: 1}, "/api/1/organizations", nil, 1},
+		{"idempotent", policy, "/api/1/organizations", nil, 3},
+		{"mutation", policy, "/api/1/deliveries/create", nil, 1},
+		{"unregistered", policy, "/api/1/unknown/mutation", nil, 1},
+		{"mutation marked safe", policy, "/api/1/deliveries/create", []Option{WithRetrySafe()}, 3},
+		{"unregistered marked safe", policy, "/api/1/unknown/mutation", []Option{WithRetrySafe()}, 3},
+		{"unregistered allowed by policy", allowAll, "/api/1/unknown/mutation", nil, 3},
+		{"idempotent denied by policy", denyAll, "/api/1/organizations", nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.attempts(tt.endpoint, tt.opts); got != tt.want {
+				t.Errorf("attempts = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	retryAfter := func(v string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": []string{v}}}
+	}
+
+	tests := []struct {
+		name    string
+		attempt int
+		resp    *http.Response
+		want    time.Duration
+	}{
+		{"first retry", 1, nil, 100 * time.Millisecond},
+		{"doubles", 2, nil, 200 * time.Millisecond},
+		{"doubles again", 3, nil, 400 * time.Millisecond},
+		{"capped", 10, nil, time.Second},
+		{"retry-after seconds", 1, retryAfter("1"), time.Second},
+		{"retry-after below cap", 1, &http.Response{Header: http.Header{"Retry-After": []string{"0"}}}, 0},
+		{"retry-after capped", 1, retryAfter("3600"), time.Second},
+		{"invalid retry-after", 2, retryAfter("soon"), 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.backoff(tt.attempt, tt.resp); got != tt.want {
+				t.Errorf("backoff = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: time.Second, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if d := policy.backoff(1, nil); d < 500*time.Millisecond || d > time.Second {
+			t.Fatalf("backoff = %v, want within [500ms, 1s]", d)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	tests := []struct {
+		value           string
+		ok              bool
+		atLeast, atMost time.Duration
+	}{
+		{"", false, 0, 0},
+		{"-1", false, 0, 0},
+		{"abc", false, 0, 0},
+		{"5", true, 5 * time.Second, 5 * time.Second},
+		{future, true, 58 * time.Minute, time.Hour},
+		{past, true, 0, 0},
+	}
+	for _, tt := range tests {
+		d, ok := parseRetryAfter(tt.value)
+		if ok != tt.ok || d < tt.atLeast || d > tt.atMost {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v in [%v, %v]", tt.value, d, ok, tt.ok, tt.atLeast, tt.atMost)
+		}
+	}
+}
+
+func TestRetryTransientFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		opts     []Option
+		status   int
+		calls    int
+		wantErr  bool
+	}{
+		{"idempotent 503 retried", "/api/1/organizations", nil, http.StatusServiceUnavailable, 3, false},
+		{"idempotent 429 retried", "/api/1/organizations", nil, http.StatusTooManyRequests, 3, false},
+		{"idempotent 400 not retried", "/api/1/organizations", nil, http.StatusBadRequest, 1, true},
+		{"unregistered 503 not retried", "/api/1/unregistered/mutation", nil, http.StatusServiceUnavailable, 1, true},
+		{"unregistered marked safe", "/api/1/unregistered/mutation", []Option{WithRetrySafe()}, http.StatusServiceUnavailable, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := 2
+			srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if failures > 0 {
+					failures--
+					writeJSON(w, tt.status, `{"correlationId":"`+testCorrelationID+`","errorDescription":"fail"}`)
+					return
+				}
+				writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+			})
+			c := newTestClient(t, srv, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+
+			var resp struct{}
+			err := c.post(context.Background(), true, tt.endpoint, struct{}{}, &resp, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := srv.callCount(tt.endpoint); got != tt.calls {
+				t.Errorf("calls = %d, want %d", got, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRetryStopsWhenContextDone(t *testing.T) {
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusServiceUnavailable, `{}`)
+	})
+	c := newTestClient(t, srv, WithRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Organizations(ctx, &OrganizationsRequest{})
+	if err == nil {
+		t.Fatal("want error")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("backoff ignored context cancellation")
+	}
+	if got := srv.callCount("/api/1/organizations"); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
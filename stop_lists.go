@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -23,15 +25,11 @@ type TerminalGroupStopListItem struct {
 	ProductID uuid.UUID `json:"productId"`
 }
 
+var stopListsEndpoint = NewEndpoint[StopListsRequest, StopListsResponse](EndpointInfo{Path: "/api/1/stop_lists", RequiresAuth: true, Idempotent: true})
+
 // Out-of-stock items.
 //
 // iiko API: /api/1/stop_lists
-func (c *Client) StopLists(req *StopListsRequest, opts ...Option) (*StopListsResponse, error) {
-	var response StopListsResponse
-
-	if err := c.post(true, "/api/1/stop_lists", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) StopLists(ctx context.Context, req *StopListsRequest, opts ...Option) (*StopListsResponse, error) {
+	return stopListsEndpoint.Call(ctx, c, req, opts...)
 }
@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -43,17 +45,13 @@ type TerminalGroupsResponse struct {
 	TerminalGroups []TerminalGroup `json:"terminalGroups"`
 }
 
+var terminalGroupsEndpoint = NewEndpoint[TerminalGroupsRequest, TerminalGroupsResponse](EndpointInfo{Path: "/api/1/terminal_groups", RequiresAuth: true, Idempotent: true})
+
 // Method that returns information on groups of delivery terminals.
 //
 // iiko API: /api/1/terminal_groups
-func (c *Client) TerminalGroups(req *TerminalGroupsRequest, opts ...Option) (*TerminalGroupsResponse, error) {
-	var response TerminalGroupsResponse
-
-	if err := c.post(true, "/api/1/terminal_groups", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) TerminalGroups(ctx context.Context, req *TerminalGroupsRequest, opts ...Option) (*TerminalGroupsResponse, error) {
+	return terminalGroupsEndpoint.Call(ctx, c, req, opts...)
 }
 
 type TerminalGroupsIsAliveRequest struct {
@@ -87,15 +85,11 @@ type TerminalGroupsIsAliveResponse struct {
 	IsAliveStatus []TerminalGroupAliveInfo `json:"isAliveStatus"`
 }
 
+var terminalGroupsIsAliveEndpoint = NewEndpoint[TerminalGroupsIsAliveRequest, TerminalGroupsIsAliveResponse](EndpointInfo{Path: "/api/1/terminal_groups/is_alive", RequiresAuth: true, Idempotent: true})
+
 // Returns information on availability of group of terminals.
 //
 // iiko API: /api/1/terminal_groups/is_alive
-func (c *Client) TerminalGroupsIsAlive(req *TerminalGroupsIsAliveRequest, opts ...Option) (*TerminalGroupsIsAliveResponse, error) {
-	var response TerminalGroupsIsAliveResponse
-
-	if err := c.post(true, "/api/1/terminal_groups/is_alive", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) TerminalGroupsIsAlive(ctx context.Context, req *TerminalGroupsIsAliveRequest, opts ...Option) (*TerminalGroupsIsAliveResponse, error) {
+	return terminalGroupsIsAliveEndpoint.Call(ctx, c, req, opts...)
 }
@@ -0,0 +1,95 @@
+package iiko
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Time is an iikoCloud timestamp, encoded in JSON as IikoTimeLayout
+// ("2006-01-02 15:04:05.000") and as null when zero.
+//
+// iikoCloud timestamps carry no time zone: they are wall clock times of the
+// organization (the terminal's time zone). A decoded Time holds that wall
+// clock in time.UTC, which is not the actual instant; use InLocation with the
+// organization's location to get it. To send an instant, convert it with
+// NewTime, which encodes its wall clock in the organization's location.
+type Time struct {
+	time.Time
+}
+
+// EventTime is the former name of Time.
+//
+// Deprecated: Use Time.
+type EventTime = Time
+
+// parseLayouts are the zoneless layouts iikoCloud uses: IikoTimeLayout and
+// ISO 8601 with any number of fractional digits, or none, and bare dates.
+var parseLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.DateOnly,
+}
+
+// NewTime returns the iiko timestamp of instant t in the organization time zone loc.
+func NewTime(t time.Time, loc *time.Location) Time {
+	return Time{Time: t.In(loc)}
+}
+
+// ParseTime parses an iiko timestamp: IikoTimeLayout or ISO 8601 without a
+// zone ("2006-01-02T15:04:05"), with or without fractional seconds, a date
+// ("2006-01-02"), which is midnight, or RFC 3339.
+func ParseTime(s string) (Time, error) {
+	for _, layout := range parseLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Time{Time: t}, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return Time{Time: t}, nil
+	}
+	return Time{}, fmt.Errorf("iiko: cannot parse time %q", s)
+}
+
+// InLocation returns the instant t denotes if its wall clock is in the
+// organization time zone loc. Unlike In, it keeps the wall clock and changes
+// the instant.
+func (t Time) InLocation(loc *time.Location) time.Time {
+	if t.IsZero() {
+		return time.Time{}
+	}
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+	return time.Date(year, month, day, hour, min, sec, t.Nanosecond(), loc)
+}
+
+// String returns t in IikoTimeLayout.
+func (t Time) String() string {
+	return t.Format(IikoTimeLayout)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + t.Format(IikoTimeLayout) + `"`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) || bytes.Equal(data, []byte(`""`)) {
+		t.Time = time.Time{}
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("iiko: cannot parse time %s", data)
+	}
+
+	parsed, err := ParseTime(string(data[1 : len(data)-1]))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
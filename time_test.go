@@ -0,0 +1,132 @@
+package iiko
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2024-01-02 10:11:12.345", want: time.Date(2024, 1, 2, 10, 11, 12, 345e6, time.UTC)},
+		{in: "2024-01-02 10:11:12", want: time.Date(2024, 1, 2, 10, 11, 12, 0, time.UTC)},
+		{in: "2024-01-02 10:11:12.1234567", want: time.Date(2024, 1, 2, 10, 11, 12, 123456700, time.UTC)},
+		{in: "2024-01-02T10:11:12", want: time.Date(2024, 1, 2, 10, 11, 12, 0, time.UTC)},
+		{in: "2024-01-02T10:11:12.5", want: time.Date(2024, 1, 2, 10, 11, 12, 5e8, time.UTC)},
+		{in: "2024-01-02", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{in: "2024-01-02T10:11:12+03:00", want: time.Date(2024, 1, 2, 7, 11, 12, 0, time.UTC)},
+		{in: "2024-01-02T10:11:12Z", want: time.Date(2024, 1, 2, 10, 11, 12, 0, time.UTC)},
+		{in: "02.01.2024", wantErr: true},
+		{in: "10:11", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTime(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.in, got.Time, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeJSON(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name     string
+		json     string
+		want     time.Time
+		wantJSON string
+	}{
+		{"iiko layout", `"2024-01-02 10:11:12.345"`, time.Date(2024, 1, 2, 10, 11, 12, 345e6, time.UTC), `"2024-01-02 10:11:12.345"`},
+		{"date only", `"2024-01-02"`, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), `"2024-01-02 00:00:00.000"`},
+		{"iso without zone", `"2024-01-02T10:11:12"`, time.Date(2024, 1, 2, 10, 11, 12, 0, time.UTC), `"2024-01-02 10:11:12.000"`},
+		{"null", `null`, time.Time{}, `null`},
+		{"empty", `""`, time.Time{}, `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Time
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got.Time, tt.want)
+			}
+
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(data) != tt.wantJSON {
+				t.Errorf("Marshal = %s, want %s", data, tt.wantJSON)
+			}
+		})
+	}
+
+	t.Run("organization time zone", func(t *testing.T) {
+		instant := time.Date(2024, 1, 2, 7, 11, 12, 0, time.UTC)
+		data, err := json.Marshal(NewTime(instant, moscow))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `"2024-01-02 10:11:12.000"`; string(data) != want {
+			t.Errorf("Marshal = %s, want %s", data, want)
+		}
+	})
+}
+
+func TestTimeInLocation(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	wall := Time{Time: time.Date(2024, 1, 2, 10, 11, 12, 0, time.UTC)}
+
+	tests := []struct {
+		name string
+		got  time.Time
+		want time.Time
+	}{
+		// InLocation keeps the wall clock and moves the instant.
+		{"InLocation", wall.InLocation(moscow), time.Date(2024, 1, 2, 7, 11, 12, 0, time.UTC)},
+		// In is time.Time.In: it keeps the instant.
+		{"In", wall.In(moscow), time.Date(2024, 1, 2, 10, 11, 12, 0, time.UTC)},
+		{"zero", Time{}.InLocation(moscow), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeFieldsAcceptIikoLayouts(t *testing.T) {
+	var card Card
+	if err := json.Unmarshal([]byte(`{"validToDate":"2025-12-31"}`), &card); err != nil {
+		t.Fatalf("Card: %v", err)
+	}
+	if want := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC); !card.ValidToDate.Equal(want) {
+		t.Errorf("ValidToDate = %v, want %v", card.ValidToDate.Time, want)
+	}
+
+	var combo MenuCombo
+	if err := json.Unmarshal([]byte(`{"startDate":"2024-01-02T10:11:12","expirationDate":"2024-02-01"}`), &combo); err != nil {
+		t.Fatalf("MenuCombo: %v", err)
+	}
+	if want := time.Date(2024, 1, 2, 10, 11, 12, 0, time.UTC); !combo.StartDate.Equal(want) {
+		t.Errorf("StartDate = %v, want %v", combo.StartDate.Time, want)
+	}
+	if want := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC); !combo.ExpirationDate.Equal(want) {
+		t.Errorf("ExpirationDate = %v, want %v", combo.ExpirationDate.Time, want)
+	}
+}
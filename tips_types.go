@@ -1,6 +1,8 @@
 package iiko
 
 import (
+	"context"
+
 	"github.com/google/uuid"
 )
 
@@ -33,15 +35,11 @@ type TipsTypesResponse struct {
 	TipsTypes []TipsType `json:"paymentTypes"`
 }
 
+var tipsTypesEndpoint = NewEndpoint[TipsTypesRequest, TipsTypesResponse](EndpointInfo{Path: "/api/1/tips_types", RequiresAuth: true, Idempotent: true})
+
 // Get tips tipes for api-login`s rms group. Allowed from version 7.7.4.
 //
 // iiko API: /api/1/tips_types
-func (c *Client) TipsTypes(req *TipsTypesRequest, opts ...Option) (*TipsTypesResponse, error) {
-	var response TipsTypesResponse
-
-	if err := c.post(true, "/api/1/tips_types", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) TipsTypes(ctx context.Context, req *TipsTypesRequest, opts ...Option) (*TipsTypesResponse, error) {
+	return tipsTypesEndpoint.Call(ctx, c, req, opts...)
 }
@@ -0,0 +1,256 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// tokenRefreshRetryDelay is how long the background refresher waits after a
+// failed refresh before trying again.
+const tokenRefreshRetryDelay = 30 * time.Second
+
+// tokenRefreshTimeout bounds a shared token refresh, including the wait for
+// the lock of a TokenStore.
+const tokenRefreshTimeout = time.Minute
+
+// Clock abstracts time for token management, the circuit breaker and the
+// default cache store so that they can be tested.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithClock replaces the clock used to track token expiry, circuit breaker
+// timeouts and the expiry of entries in the default cache store.
+func WithClock(clock Clock) ClientOption {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// OnTokenRefreshError registers fn to be called with the error of every
+// failed access token refresh, including the background ones that have no
+// caller to return the error to.
+func OnTokenRefreshError(fn func(err error)) ClientOption {
+	return func(c *Client) {
+		c.onTokenRefreshError = fn
+	}
+}
+
+// accessTokenState is the current access token with its validity window.
+type accessTokenState struct {
+	value     string
+	issuedAt  time.Time
+	expiresAt time.Time
+}
+
+// tokenRefresh is a refresh in flight that concurrent callers wait for
+// instead of requesting a token of their own.
+type tokenRefresh struct {
+	done chan struct{}
+	err  error
+
+	// cancel aborts the refresh.
+	cancel context.CancelFunc
+	// waiters is the number of callers waiting for done, guarded by Client.tokenMu.
+	waiters int
+}
+
+// TokenExpiresAt returns when the current access token expires. Zero if the
+// Client has no token.
+func (c *Client) TokenExpiresAt() time.Time {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.token.expiresAt
+}
+
+// getToken returns the current access token in a thread-safe way.
+func (c *Client) getToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.token.value
+}
+
+// setToken stores a new access token issued just now in a thread-safe way.
+func (c *Client) setToken(token string) {
+	now := c.clock.Now()
+	c.setTokenState(accessTokenState{
+		value:     token,
+		issuedAt:  now,
+		expiresAt: now.Add(DefaultTokenLifetime),
+	})
+}
+
+// setTokenState stores an access token with its validity window in a thread-safe way.
+func (c *Client) setTokenState(token accessTokenState) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.token = token
+}
+
+// validToken returns the current access token, refreshing it first if it has
+// already expired, e.g. because the background refresher kept failing, or if
+// a lazy Client has not fetched one yet.
+func (c *Client) validToken(ctx context.Context) (string, error) {
+	c.tokenMu.RLock()
+	token := c.token
+	c.tokenMu.RUnlock()
+
+	if token.value == "" && !c.lazy {
+		return "", ErrMissingToken
+	}
+	if token.value != "" && c.clock.Now().Before(token.expiresAt) {
+		return token.value, nil
+	}
+
+	if err := c.refreshTokenAfter(ctx, token.value); err != nil {
+		return "", err
+	}
+	return c.getToken(), nil
+}
+
+// refreshAt returns when the background refresher should renew the token.
+func (c *Client) refreshAt() time.Time {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+
+	at := c.token.issuedAt.Add(c.refreshTokenInterval)
+	if at.After(c.token.expiresAt) {
+		at = c.token.expiresAt
+	}
+	return at
+}
+
+// refreshTokenAfter refreshes the token unless it has already been replaced
+// since stale was read, so that a burst of 401s leads to a single refresh.
+func (c *Client) refreshTokenAfter(ctx context.Context, stale string) error {
+	if c.getToken() != stale {
+		return nil
+	}
+	return c.refreshToken(ctx)
+}
+
+// refreshToken fetches a fresh access token from iikoCloud and stores it.
+// Concurrent calls share one request. On any error it keeps the previous
+// token untouched so in-flight requests keep working until the next
+// successful refresh.
+//
+// The shared request is not bound to the cancellation of a single ctx, since
+// other callers may be waiting for it. The caller stops waiting as soon as
+// its ctx is done, and the request is aborted once no caller waits for it
+// anymore, after tokenRefreshTimeout, or by Close.
+func (c *Client) refreshToken(ctx context.Context) error {
+	c.tokenMu.Lock()
+	r := c.refreshing
+	if r == nil {
+		var refreshCtx context.Context
+		r = &tokenRefresh{done: make(chan struct{})}
+		refreshCtx, r.cancel = context.WithTimeout(context.WithoutCancel(ctx), tokenRefreshTimeout)
+		c.refreshing = r
+		go c.runRefresh(refreshCtx, r)
+	}
+	r.waiters++
+	c.tokenMu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		c.tokenMu.Lock()
+		r.waiters--
+		if r.waiters == 0 && c.refreshing == r {
+			// Callers that come later start a refresh of their own
+			// instead of joining the abandoned one.
+			c.refreshing = nil
+			r.cancel()
+		}
+		c.tokenMu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (c *Client) runRefresh(ctx context.Context, r *tokenRefresh) {
+	defer r.cancel()
+	go func() {
+		select {
+		case <-c.quit:
+			r.cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if c.tokenStore != nil {
+		r.err = c.fetchSharedToken(ctx)
+	} else {
+		r.err = c.fetchToken(ctx)
+	}
+
+	c.tokenMu.Lock()
+	abandoned := c.refreshing != r
+	if !abandoned {
+		c.refreshing = nil
+	}
+	c.tokenMu.Unlock()
+	close(r.done)
+
+	// Either the first token of the Client or a renewal; the refresher is
+	// started once and only after a token has been obtained.
+	if r.err == nil {
+		c.startRefresher()
+	}
+
+	// An abandoned refresh fails because nobody waits for it anymore.
+	if r.err != nil && !abandoned && c.onTokenRefreshError != nil {
+		c.onTokenRefreshError(r.err)
+	}
+}
+
+// fetchToken performs the access token request and stores the result.
+func (c *Client) fetchToken(ctx context.Context) (err error) {
+	if c.metrics != nil {
+		defer func() {
+			c.metrics.ObserveTokenRefresh(err)
+		}()
+	}
+
+	resp, err := c.accessToken(ctx, &AccessTokenRequest{
+		ApiLogin:     c.apiLogin,
+		AppId:        c.appId,
+		ClientSecret: c.clientSecret,
+	})
+	if err != nil {
+		return err
+	}
+	if resp == nil || resp.Token == "" {
+		return errors.New("iiko: empty access token in response")
+	}
+	c.setToken(resp.Token)
+	return nil
+}
+
+// refreshTokenByInterval renews the token refreshTokenInterval after it was
+// issued, well before it expires. A failed refresh keeps the old (still
+// valid) token and is retried after tokenRefreshRetryDelay.
+func (c *Client) refreshTokenByInterval() {
+	for {
+		select {
+		case <-c.clock.After(c.refreshAt().Sub(c.clock.Now())):
+		case <-c.quit:
+			return
+		}
+
+		if err := c.refreshToken(context.Background()); err != nil {
+			select {
+			case <-c.clock.After(tokenRefreshRetryDelay):
+			case <-c.quit:
+				return
+			}
+		}
+	}
+}
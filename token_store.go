@@ -0,0 +1,370 @@
+package iiko
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// tokenLockTTL bounds how long one Client may own the refresh of a shared token.
+const tokenLockTTL = 30 * time.Second
+
+// tokenPollInterval is how often a Client that does not own the refresh checks
+// the store for the token fetched by the owner.
+const tokenPollInterval = 250 * time.Millisecond
+
+// StoredToken is an access token shared through a TokenStore.
+type StoredToken struct {
+	Value     string    `json:"value"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// TokenStore shares access tokens between Clients, possibly running in
+// different processes, so that one token per apiLogin/appId is fetched instead
+// of one per Client. Keys are derived from apiLogin and appId and never contain
+// them in clear text.
+//
+// MemoryTokenStore and FileTokenStore are shipped with the package; a Redis
+// backend maps TryLock onto SET NX PX and Unlock onto a compare-and-delete.
+type TokenStore interface {
+	// Load returns the token stored under key, or nil if there is none.
+	Load(ctx context.Context, key string) (*StoredToken, error)
+
+	// Save stores token under key.
+	Save(ctx context.Context, key string, token StoredToken) error
+
+	// TryLock makes owner the one who refreshes the token under key, for ttl
+	// at most. It reports false if another owner holds an unexpired lock.
+	TryLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Unlock releases the lock under key if it is held by owner.
+	Unlock(ctx context.Context, key, owner string) error
+}
+
+// WithTokenStore makes the Client share its access token through s.
+func WithTokenStore(s TokenStore) ClientOption {
+	return func(c *Client) {
+		c.tokenStore = s
+	}
+}
+
+// tokenStoreKey identifies the token of an apiLogin/appId pair in a TokenStore.
+func tokenStoreKey(apiLogin, appId string) string {
+	sum := sha256.Sum256([]byte(apiLogin + "\x00" + appId))
+	return "iiko-token-" + hex.EncodeToString(sum[:16])
+}
+
+// fetchSharedToken obtains a fresh token through the Client's TokenStore:
+// it adopts a token another Client has already stored, or takes the refresh
+// lock, fetches the token from iikoCloud and stores it. If the lock is held
+// elsewhere it waits for the owner's token, and fetches one itself should the
+// owner not deliver it in time.
+func (c *Client) fetchSharedToken(ctx context.Context) error {
+	key := tokenStoreKey(c.apiLogin, c.appId)
+	stale := c.getToken()
+
+	if ok, err := c.adoptStoredToken(ctx, key, stale); err != nil || ok {
+		return err
+	}
+
+	deadline := c.clock.Now().Add(tokenLockTTL)
+	for {
+		locked, err := c.tokenStore.TryLock(ctx, key, c.tokenOwner, tokenLockTTL)
+		if err != nil {
+			return err
+		}
+
+		if locked {
+			defer c.tokenStore.Unlock(context.WithoutCancel(ctx), key, c.tokenOwner)
+
+			// Someone may have stored a token between our first look and the lock.
+			if ok, err := c.adoptStoredToken(ctx, key, stale); err != nil || ok {
+				return err
+			}
+			return c.fetchAndStoreToken(ctx, key)
+		}
+
+		if !c.clock.Now().Before(deadline) {
+			return c.fetchAndStoreToken(ctx, key)
+		}
+
+		select {
+		case <-c.clock.After(tokenPollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if ok, err := c.adoptStoredToken(ctx, key, stale); err != nil || ok {
+			return err
+		}
+	}
+}
+
+// adoptStoredToken switches to the stored token if it differs from stale and
+// is not yet due for refresh.
+func (c *Client) adoptStoredToken(ctx context.Context, key, stale string) (bool, error) {
+	stored, err := c.tokenStore.Load(ctx, key)
+	if err != nil || stored == nil || stored.Value == "" || stored.Value == stale {
+		return false, err
+	}
+
+	refreshAt := stored.IssuedAt.Add(c.refreshTokenInterval)
+	if refreshAt.After(stored.ExpiresAt) {
+		refreshAt = stored.ExpiresAt
+	}
+	if !c.clock.Now().Before(refreshAt) {
+		return false, nil
+	}
+
+	c.setTokenState(accessTokenState{
+		value:     stored.Value,
+		issuedAt:  stored.IssuedAt,
+		expiresAt: stored.ExpiresAt,
+	})
+	return true, nil
+}
+
+// fetchAndStoreToken fetches a token and stores it under key. A token that
+// cannot be stored is still used; the error is logged, since other Clients
+// merely fetch tokens of their own.
+func (c *Client) fetchAndStoreToken(ctx context.Context, key string) error {
+	if err := c.fetchToken(ctx); err != nil {
+		return err
+	}
+
+	c.tokenMu.RLock()
+	token := c.token
+	c.tokenMu.RUnlock()
+
+	err := c.tokenStore.Save(ctx, key, StoredToken{
+		Value:     token.value,
+		IssuedAt:  token.issuedAt,
+		ExpiresAt: token.expiresAt,
+	})
+	if err != nil && c.logger != nil {
+		c.logger.WarnContext(ctx, "iiko token store save failed", slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// newTokenOwner returns an ID that tells this Client apart in a TokenStore.
+func newTokenOwner() string {
+	return uuid.New().String()
+}
+
+// MemoryTokenStore is a TokenStore shared by Clients of one process.
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]StoredToken
+	locks  map[string]memoryTokenLock
+}
+
+type memoryTokenLock struct {
+	owner string
+	until time.Time
+}
+
+// NewMemoryTokenStore creates an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[string]StoredToken),
+		locks:  make(map[string]memoryTokenLock),
+	}
+}
+
+// Load implements TokenStore.
+func (s *MemoryTokenStore) Load(_ context.Context, key string) (*StoredToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[key]
+	if !ok {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// Save implements TokenStore.
+func (s *MemoryTokenStore) Save(_ context.Context, key string, token StoredToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[key] = token
+	return nil
+}
+
+// TryLock implements TokenStore.
+func (s *MemoryTokenStore) TryLock(_ context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l, ok := s.locks[key]; ok && l.owner != owner && time.Now().Before(l.until) {
+		return false, nil
+	}
+	s.locks[key] = memoryTokenLock{owner: owner, until: time.Now().Add(ttl)}
+	return true, nil
+}
+
+// Unlock implements TokenStore.
+func (s *MemoryTokenStore) Unlock(_ context.Context, key, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l, ok := s.locks[key]; ok && l.owner == owner {
+		delete(s.locks, key)
+	}
+	return nil
+}
+
+// FileTokenStore is a TokenStore that keeps tokens in files of one directory,
+// e.g. on a volume shared by replicas of a service on the same host.
+//
+// Lock files are only read and replaced while holding a flock on a guard file
+// of the key, so that no two owners ever hold a lock at once. Where flock is
+// not available, e.g. on Windows, locks only exclude Clients of one process.
+type FileTokenStore struct {
+	dir string
+}
+
+// NewFileTokenStore creates a FileTokenStore in dir, creating it if needed.
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileTokenStore{dir: dir}, nil
+}
+
+type fileTokenLock struct {
+	Owner string    `json:"owner"`
+	Until time.Time `json:"until"`
+}
+
+// Load implements TokenStore.
+func (s *FileTokenStore) Load(_ context.Context, key string) (*StoredToken, error) {
+	data, err := os.ReadFile(s.path(key, ".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var token StoredToken
+	if err = json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("iiko: corrupt token file: %w", err)
+	}
+	return &token, nil
+}
+
+// Save implements TokenStore. The file is replaced atomically so that readers
+// never see a partially written token.
+func (s *FileTokenStore) Save(_ context.Context, key string, token StoredToken) error {
+	return s.writeJSON(key, ".json", token)
+}
+
+// TryLock implements TokenStore. An expired lock file left behind by a crashed
+// owner is taken over.
+func (s *FileTokenStore) TryLock(_ context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	unlock, err := lockFile(s.path(key, ".guard"))
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	held, err := s.readLock(s.path(key, ".lock"))
+	if err != nil {
+		return false, err
+	}
+	if held != nil && held.Owner != owner && time.Now().Before(held.Until) {
+		return false, nil
+	}
+
+	if err = s.writeJSON(key, ".lock", fileTokenLock{Owner: owner, Until: time.Now().Add(ttl)}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Unlock implements TokenStore.
+func (s *FileTokenStore) Unlock(_ context.Context, key, owner string) error {
+	unlock, err := lockFile(s.path(key, ".guard"))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	path := s.path(key, ".lock")
+	held, err := s.readLock(path)
+	if err != nil || held == nil || held.Owner != owner {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// writeJSON replaces the file of key with the JSON encoding of v through a
+// temporary file renamed over it.
+func (s *FileTokenStore) writeJSON(key, ext string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(key, ext))
+}
+
+// readLock returns nil if there is no lock file. A lock file that can not be
+// parsed, e.g. a corrupt one, is reported as held by nobody until its ttl
+// could have passed.
+func (s *FileTokenStore) readLock(path string) (*fileTokenLock, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var l fileTokenLock
+	if err = json.Unmarshal(data, &l); err != nil {
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			return nil, nil
+		}
+		return &fileTokenLock{Until: info.ModTime().Add(tokenLockTTL)}, nil
+	}
+	return &l, nil
+}
+
+func (s *FileTokenStore) path(key, ext string) string {
+	return filepath.Join(s.dir, strings.ReplaceAll(key, string(filepath.Separator), "_")+ext)
+}
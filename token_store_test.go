@@ -0,0 +1,236 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenStores returns a fresh store of every kind shipped with the package.
+func tokenStores(t *testing.T) map[string]TokenStore {
+	t.Helper()
+
+	file, err := NewFileTokenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]TokenStore{
+		"memory": NewMemoryTokenStore(),
+		"file":   file,
+	}
+}
+
+func TestTokenStoreLocks(t *testing.T) {
+	type step struct {
+		op    string // "lock" or "unlock"
+		owner string
+		ttl   time.Duration
+		want  bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"free lock", []step{
+			{"lock", "a", time.Minute, true},
+		}},
+		{"held by another owner", []step{
+			{"lock", "a", time.Minute, true},
+			{"lock", "b", time.Minute, false},
+		}},
+		{"relocked by its owner", []step{
+			{"lock", "a", time.Minute, true},
+			{"lock", "a", time.Minute, true},
+		}},
+		{"released", []step{
+			{"lock", "a", time.Minute, true},
+			{"unlock", "a", 0, false},
+			{"lock", "b", time.Minute, true},
+		}},
+		{"released by another owner", []step{
+			{"lock", "a", time.Minute, true},
+			{"unlock", "b", 0, false},
+			{"lock", "b", time.Minute, false},
+		}},
+		{"expired", []step{
+			{"lock", "a", -time.Second, true},
+			{"lock", "b", time.Minute, true},
+			{"lock", "a", time.Minute, false},
+		}},
+	}
+	for _, tt := range tests {
+		for kind, store := range tokenStores(t) {
+			t.Run(kind+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				for i, s := range tt.steps {
+					if s.op == "unlock" {
+						if err := store.Unlock(ctx, "key-"+tt.name, s.owner); err != nil {
+							t.Fatalf("step %d: %v", i, err)
+						}
+						continue
+					}
+					got, err := store.TryLock(ctx, "key-"+tt.name, s.owner, s.ttl)
+					if err != nil {
+						t.Fatalf("step %d: %v", i, err)
+					}
+					if got != s.want {
+						t.Fatalf("step %d: TryLock(%s) = %v, want %v", i, s.owner, got, s.want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestTokenStoreLockContention(t *testing.T) {
+	tests := []struct {
+		name string
+		// expired leaves an expired lock of a crashed owner behind first.
+		expired bool
+	}{
+		{"free lock", false},
+		{"expired lock", true},
+	}
+	for _, tt := range tests {
+		for kind, store := range tokenStores(t) {
+			t.Run(kind+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				for round := 0; round < 20; round++ {
+					key := fmt.Sprintf("key-%d", round)
+					if tt.expired {
+						if _, err := store.TryLock(ctx, key, "crashed", -time.Second); err != nil {
+							t.Fatal(err)
+						}
+					}
+
+					var winners atomic.Int32
+					var wg sync.WaitGroup
+					for i := 0; i < 8; i++ {
+						wg.Add(1)
+						go func(owner string) {
+							defer wg.Done()
+							ok, err := store.TryLock(ctx, key, owner, time.Minute)
+							if err != nil {
+								t.Error(err)
+							}
+							if ok {
+								winners.Add(1)
+							}
+						}(fmt.Sprint("owner-", i))
+					}
+					wg.Wait()
+
+					if got := winners.Load(); got != 1 {
+						t.Fatalf("round %d: %d owners hold the lock", round, got)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestTokenStoreSaveLoad(t *testing.T) {
+	for kind, store := range tokenStores(t) {
+		t.Run(kind, func(t *testing.T) {
+			ctx := context.Background()
+
+			got, err := store.Load(ctx, "key")
+			if err != nil || got != nil {
+				t.Fatalf("Load of a missing token = %v, %v", got, err)
+			}
+
+			now := time.Now().UTC().Truncate(time.Second)
+			want := StoredToken{Value: "token", IssuedAt: now, ExpiresAt: now.Add(time.Hour)}
+			if err = store.Save(ctx, "key", want); err != nil {
+				t.Fatal(err)
+			}
+			got, err = store.Load(ctx, "key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil || got.Value != want.Value || !got.IssuedAt.Equal(want.IssuedAt) || !got.ExpiresAt.Equal(want.ExpiresAt) {
+				t.Errorf("Load = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestFileTokenStoreCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileTokenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "key.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Load(context.Background(), "key"); err == nil {
+		t.Error("Load of a corrupt token succeeded")
+	}
+
+	// A corrupt lock file is held until its ttl could have passed.
+	if err = os.WriteFile(filepath.Join(dir, "key.lock"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := store.TryLock(context.Background(), "key", "a", time.Minute); err != nil || ok {
+		t.Errorf("TryLock over a fresh corrupt lock = %v, %v", ok, err)
+	}
+}
+
+func TestTokenStoreKey(t *testing.T) {
+	a, b := tokenStoreKey("login", "app"), tokenStoreKey("login", "")
+	if a == b || a != tokenStoreKey("login", "app") {
+		t.Errorf("keys %q and %q", a, b)
+	}
+	if filepath.Base(a) != a || len(a) > 64 {
+		t.Errorf("key %q is not a plain file name", a)
+	}
+}
+
+// failingSaveStore is a MemoryTokenStore whose Save always fails.
+type failingSaveStore struct {
+	*MemoryTokenStore
+}
+
+func (failingSaveStore) Save(context.Context, string, StoredToken) error {
+	return errors.New("store is read-only")
+}
+
+func TestSharedToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		store func() TokenStore
+		// wantRequests is the number of token requests made by two Clients.
+		wantRequests int32
+	}{
+		{"shared", func() TokenStore { return NewMemoryTokenStore() }, 1},
+		{"save fails", func() TokenStore { return failingSaveStore{NewMemoryTokenStore()} }, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTokenServer(t, nil)
+			store := tt.store()
+
+			for i := 0; i < 2; i++ {
+				c, err := NewClient("test", WithBaseURL(srv.URL), WithTokenStore(store))
+				if err != nil {
+					t.Fatalf("NewClient: %v", err)
+				}
+				defer c.Close()
+				if c.getToken() != "token" {
+					t.Errorf("client %d token = %q", i, c.getToken())
+				}
+			}
+			if got := srv.requests.Load(); got != tt.wantRequests {
+				t.Errorf("token requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
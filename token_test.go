@@ -0,0 +1,267 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenServer issues access tokens after release is closed, or at once if it is nil.
+type tokenServer struct {
+	*httptest.Server
+
+	release  chan struct{}
+	requests atomic.Int32
+	// aborted counts token requests whose client went away.
+	aborted atomic.Int32
+}
+
+func newTokenServer(t *testing.T, release chan struct{}) *tokenServer {
+	t.Helper()
+
+	s := &tokenServer{release: release}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/1/access_token" {
+			writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`"}`)
+			return
+		}
+		s.requests.Add(1)
+		// The server notices a client going away only once the body is read.
+		_, _ = io.Copy(io.Discard, r.Body)
+		if s.release != nil {
+			select {
+			case <-s.release:
+			case <-r.Context().Done():
+				s.aborted.Add(1)
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, `{"correlationId":"`+testCorrelationID+`","token":"token"}`)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func newLazyClient(t *testing.T, url string, opts ...ClientOption) *Client {
+	t.Helper()
+
+	c, err := NewClient("test", append([]ClientOption{WithBaseURL(url), WithLazyInit()}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+// waitFor polls cond, yielding to other goroutines in between, until it
+// holds or a second passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		runtime.Gosched()
+	}
+}
+
+// refreshWaiters returns how many callers wait for the token refresh of c.
+func refreshWaiters(c *Client) int {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.refreshing == nil {
+		return 0
+	}
+	return c.refreshing.waiters
+}
+
+func TestRefreshTokenSingleFlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		callers int
+	}{
+		{"one caller", 1},
+		{"concurrent callers", 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			srv := newTokenServer(t, release)
+			c := newLazyClient(t, srv.URL)
+
+			var wg sync.WaitGroup
+			errs := make(chan error, tt.callers)
+			for i := 0; i < tt.callers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- c.refreshToken(context.Background())
+				}()
+			}
+			waitFor(t, "the token request", func() bool { return srv.requests.Load() == 1 })
+			close(release)
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				if err != nil {
+					t.Error(err)
+				}
+			}
+			if got := srv.requests.Load(); got != 1 {
+				t.Errorf("token requests = %d, want 1", got)
+			}
+			if c.getToken() != "token" {
+				t.Errorf("token = %q", c.getToken())
+			}
+		})
+	}
+}
+
+func TestRefreshTokenWaiters(t *testing.T) {
+	tests := []struct {
+		name string
+		// cancelled is how many of the two waiters give up.
+		cancelled   int
+		wantAborted int32
+	}{
+		{"all waiters stay", 0, 0},
+		{"one waiter leaves", 1, 0},
+		{"all waiters leave", 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			srv := newTokenServer(t, release)
+
+			var refreshErrs atomic.Int32
+			c := newLazyClient(t, srv.URL, OnTokenRefreshError(func(error) { refreshErrs.Add(1) }))
+
+			var wg sync.WaitGroup
+			errs := make([]error, 2)
+			cancels := make([]context.CancelFunc, len(errs))
+			for i := range errs {
+				var ctx context.Context
+				ctx, cancels[i] = context.WithCancel(context.Background())
+				defer cancels[i]()
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = c.refreshToken(ctx)
+				}(i)
+			}
+
+			waitFor(t, "the token request", func() bool { return srv.requests.Load() == 1 })
+			waitFor(t, "waiters", func() bool { return refreshWaiters(c) == len(errs) })
+			for _, cancel := range cancels[:tt.cancelled] {
+				cancel()
+			}
+			waitFor(t, "waiters to leave", func() bool { return refreshWaiters(c) == len(errs)-tt.cancelled })
+			if tt.cancelled == len(errs) {
+				waitFor(t, "the abort of the token request", func() bool { return srv.aborted.Load() == 1 })
+			}
+			close(release)
+			wg.Wait()
+
+			for i, err := range errs {
+				if want := i < tt.cancelled; errors.Is(err, context.Canceled) != want {
+					t.Errorf("waiter %d: err = %v, cancelled %v", i, err, want)
+				}
+			}
+			if got := srv.aborted.Load(); got != tt.wantAborted {
+				t.Errorf("aborted token requests = %d, want %d", got, tt.wantAborted)
+			}
+			if got := refreshErrs.Load(); got != 0 {
+				t.Errorf("OnTokenRefreshError called %d times for an abandoned refresh", got)
+			}
+
+			// A caller after an abandoned refresh gets a token of its own.
+			if err := c.refreshToken(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenClose(t *testing.T) {
+	srv := newTokenServer(t, make(chan struct{}))
+	c := newLazyClient(t, srv.URL)
+
+	done := make(chan error, 1)
+	go func() { done <- c.refreshToken(context.Background()) }()
+	waitFor(t, "the token request", func() bool { return srv.requests.Load() == 1 })
+	c.Close()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("refresh succeeded after Close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close did not abort the refresh")
+	}
+}
+
+func TestValidToken(t *testing.T) {
+	tests := []struct {
+		name         string
+		advance      time.Duration
+		wantRequests int32
+	}{
+		{"fresh token", time.Minute, 1},
+		{"expired token", DefaultTokenLifetime + time.Second, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTokenServer(t, nil)
+			clock := newFakeClock()
+			c := newLazyClient(t, srv.URL, WithClock(clock))
+
+			if _, err := c.validToken(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if want := clock.Now().Add(DefaultTokenLifetime); !c.TokenExpiresAt().Equal(want) {
+				t.Errorf("TokenExpiresAt = %v, want %v", c.TokenExpiresAt(), want)
+			}
+
+			clock.Advance(tt.advance)
+			if _, err := c.validToken(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := srv.requests.Load(); got != tt.wantRequests {
+				t.Errorf("token requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenAfterStale(t *testing.T) {
+	srv := newTokenServer(t, nil)
+	c := newLazyClient(t, srv.URL)
+	if _, err := c.validToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The token was replaced since "old" was read: no refresh.
+	if err := c.refreshTokenAfter(context.Background(), "old"); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.requests.Load(); got != 1 {
+		t.Errorf("token requests = %d, want 1", got)
+	}
+
+	if err := c.refreshTokenAfter(context.Background(), c.getToken()); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.requests.Load(); got != 2 {
+		t.Errorf("token requests = %d, want 2", got)
+	}
+}
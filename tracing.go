@@ -0,0 +1,145 @@
+package iiko
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of spans produced by this package.
+const tracerName = "github.com/teztar/iiko-go"
+
+// Span attributes set on API call and webhook spans.
+const (
+	attrEndpoint       = attribute.Key("iiko.endpoint")
+	attrOrganizationID = attribute.Key("iiko.organization_id")
+	attrOrderID        = attribute.Key("iiko.order_id")
+	attrCorrelationID  = attribute.Key("iiko.correlation_id")
+	attrRetryCount     = attribute.Key("iiko.retry_count")
+	attrEventType      = attribute.Key("iiko.event_type")
+	attrStatusCode     = attribute.Key("http.response.status_code")
+)
+
+// WithTracerProvider makes the Client produce an OpenTelemetry span for every
+// API call. The span carries the endpoint, organization and order IDs found in
+// the request, HTTP status, retry count and iiko correlationId.
+func WithTracerProvider(tp trace.TracerProvider) ClientOption {
+	return func(c *Client) {
+		c.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// traceCalls is the middleware installed by WithTracerProvider.
+func traceCalls(tracer trace.Tracer) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			ids := extractIDs(call.Body)
+
+			ctx, span := tracer.Start(ctx, "iiko "+call.Endpoint,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrEndpoint.String(call.Endpoint)),
+			)
+			defer span.End()
+
+			if ids.organizationID != "" {
+				span.SetAttributes(attrOrganizationID.String(ids.organizationID))
+			}
+
+			err := next(ctx, call)
+
+			span.SetAttributes(attrStatusCode.Int(call.StatusCode))
+			if call.Attempts > 1 {
+				span.SetAttributes(attrRetryCount.Int(call.Attempts - 1))
+			}
+
+			resp := extractIDs(call.ResponseBody)
+			if resp.correlationID != "" {
+				span.SetAttributes(attrCorrelationID.String(resp.correlationID))
+			}
+			if orderID := firstNonEmpty(ids.orderID, resp.orderID); orderID != "" {
+				span.SetAttributes(attrOrderID.String(orderID))
+			}
+
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			return err
+		}
+	}
+}
+
+// startEventSpan starts the span of one webhook event handled by WebhookServer.
+// The order ID lets a DeliveryOrderUpdate be matched with the span of the
+// DeliveryCreate call that created the order.
+func startEventSpan(ctx context.Context, tracer trace.Tracer, event *WebhookEvent) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attrEventType.String(string(event.EventType)),
+		attrOrganizationID.String(event.OrganizationID.String()),
+		attrCorrelationID.String(event.CorrelationID.String()),
+	}
+
+	var info struct {
+		ID string `json:"id"`
+	}
+	if len(event.EventInfo) > 0 && json.Unmarshal(event.EventInfo, &info) == nil && info.ID != "" {
+		attrs = append(attrs, attrOrderID.String(info.ID))
+	}
+
+	return tracer.Start(ctx, "iiko webhook "+string(event.EventType),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// callIDs are the identifiers worth attaching to a span.
+type callIDs struct {
+	organizationID  string
+	terminalGroupID string
+	orderID         string
+	correlationID   string
+}
+
+// extractIDs picks identifiers out of a request or response body of any endpoint.
+func extractIDs(body []byte) callIDs {
+	var v struct {
+		OrganizationID  string   `json:"organizationId"`
+		OrganizationIDs []string `json:"organizationIds"`
+		TerminalGroupID string   `json:"terminalGroupId"`
+		CorrelationID   string   `json:"correlationId"`
+		Order           struct {
+			ID string `json:"id"`
+		} `json:"order"`
+		OrderInfo struct {
+			ID string `json:"id"`
+		} `json:"orderInfo"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &v) != nil {
+		return callIDs{}
+	}
+
+	ids := callIDs{
+		organizationID:  v.OrganizationID,
+		terminalGroupID: v.TerminalGroupID,
+		orderID:         firstNonEmpty(v.Order.ID, v.OrderInfo.ID),
+		correlationID:   v.CorrelationID,
+	}
+	if ids.organizationID == "" && len(v.OrganizationIDs) > 0 {
+		ids.organizationID = v.OrganizationIDs[0]
+	}
+
+	return ids
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
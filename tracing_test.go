@@ -0,0 +1,257 @@
+package iiko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordedSpan is a span kept by recordingTracer.
+type recordedSpan struct {
+	noop.Span
+
+	mu     sync.Mutex
+	name   string
+	kind   trace.SpanKind
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	errs   []error
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = code
+}
+
+func (s *recordedSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errs = append(s.errs, err)
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended = true
+}
+
+// recordingProvider is a TracerProvider whose tracer keeps every span it starts.
+type recordingProvider struct {
+	noop.TracerProvider
+	tracer recordingTracer
+}
+
+func (p *recordingProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return &p.tracer
+}
+
+// span returns the only span named name.
+func (p *recordingProvider) span(t *testing.T, name string) *recordedSpan {
+	t.Helper()
+	return p.tracer.span(t, name)
+}
+
+type recordingTracer struct {
+	noop.Tracer
+
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (p *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	span := &recordedSpan{name: name, kind: cfg.SpanKind(), attrs: make(map[attribute.Key]attribute.Value)}
+	span.SetAttributes(cfg.Attributes()...)
+
+	p.mu.Lock()
+	p.spans = append(p.spans, span)
+	p.mu.Unlock()
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (p *recordingTracer) span(t *testing.T, name string) *recordedSpan {
+	t.Helper()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var found *recordedSpan
+	for _, s := range p.spans {
+		if s.name == name {
+			if found != nil {
+				t.Fatalf("more than one span %q", name)
+			}
+			found = s
+		}
+	}
+	if found == nil {
+		t.Fatalf("no span %q", name)
+	}
+	return found
+}
+
+func TestTraceCalls(t *testing.T) {
+	orgID := uuid.MustParse("7bc05553-4b68-44e8-b7bc-37be63c6d9e9")
+
+	tests := []struct {
+		name       string
+		failures   int32
+		status     int
+		wantAttrs  map[attribute.Key]attribute.Value
+		wantStatus codes.Code
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			wantAttrs: map[attribute.Key]attribute.Value{
+				attrEndpoint:       attribute.StringValue("/api/1/organizations"),
+				attrOrganizationID: attribute.StringValue(orgID.String()),
+				attrCorrelationID:  attribute.StringValue(testCorrelationID),
+				attrStatusCode:     attribute.IntValue(http.StatusOK),
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name:     "retried",
+			failures: 2,
+			status:   http.StatusOK,
+			wantAttrs: map[attribute.Key]attribute.Value{
+				attrRetryCount: attribute.IntValue(2),
+				attrStatusCode: attribute.IntValue(http.StatusOK),
+			},
+			wantStatus: codes.Unset,
+		},
+		{
+			name:   "failure",
+			status: http.StatusBadRequest,
+			wantAttrs: map[attribute.Key]attribute.Value{
+				attrStatusCode:    attribute.IntValue(http.StatusBadRequest),
+				attrCorrelationID: attribute.StringValue(testCorrelationID),
+			},
+			wantStatus: codes.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failures atomic.Int32
+			failures.Store(tt.failures)
+			srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if failures.Add(-1) >= 0 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				writeJSON(w, tt.status, `{"correlationId":"`+testCorrelationID+`"}`)
+			})
+
+			tp := &recordingProvider{}
+			c := newTestClient(t, srv, WithTracerProvider(tp), WithRetryPolicy(RetryPolicy{MaxAttempts: 3}))
+
+			_, err := c.Organizations(context.Background(), &OrganizationsRequest{OrganizationIDs: []uuid.UUID{orgID}})
+			if (err != nil) != (tt.wantStatus == codes.Error) {
+				t.Fatalf("err = %v", err)
+			}
+
+			span := tp.span(t, "iiko /api/1/organizations")
+			span.mu.Lock()
+			defer span.mu.Unlock()
+			if !span.ended || span.kind != trace.SpanKindClient {
+				t.Errorf("ended %v, kind %v", span.ended, span.kind)
+			}
+			for key, want := range tt.wantAttrs {
+				if got, ok := span.attrs[key]; !ok || got != want {
+					t.Errorf("%s = %v, want %v", key, got.Emit(), want.Emit())
+				}
+			}
+			if span.status != tt.wantStatus || (tt.wantStatus == codes.Error) != (len(span.errs) == 1) {
+				t.Errorf("status = %v, errors %v", span.status, span.errs)
+			}
+		})
+	}
+}
+
+func TestTraceWebhookEvent(t *testing.T) {
+	errHandler := errors.New("handler failed")
+	event := &WebhookEvent{
+		EventType:      DeliveryOrderUpdateWebhookEvent,
+		OrganizationID: uuid.MustParse("7bc05553-4b68-44e8-b7bc-37be63c6d9e9"),
+		CorrelationID:  uuid.MustParse(testCorrelationID),
+		EventInfo:      []byte(`{"id":"a1b2c3d4-0000-0000-0000-000000000001"}`),
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus codes.Code
+	}{
+		{"handled", nil, codes.Unset},
+		{"failed", errHandler, codes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := &recordingProvider{}
+			s := NewWebhookServer("secret")
+			s.SetTracerProvider(tp)
+
+			var handlerSpan trace.Span
+			s.RegisterHandler(event.EventType, "test", func(ctx context.Context, _ *WebhookEvent) error {
+				handlerSpan = trace.SpanFromContext(ctx)
+				return tt.err
+			})
+
+			if err := s.HandleEvent(context.Background(), event, "secret"); !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+
+			span := tp.span(t, "iiko webhook DeliveryOrderUpdate")
+			if handlerSpan != trace.Span(span) {
+				t.Error("handler context does not carry the event span")
+			}
+			if !span.ended || span.kind != trace.SpanKindConsumer || span.status != tt.wantStatus {
+				t.Errorf("ended %v, kind %v, status %v", span.ended, span.kind, span.status)
+			}
+			if got := span.attrs[attrOrderID].AsString(); got != "a1b2c3d4-0000-0000-0000-000000000001" {
+				t.Errorf("order id = %q", got)
+			}
+		})
+	}
+}
+
+func TestExtractIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want callIDs
+	}{
+		{"empty", "", callIDs{}},
+		{"invalid", "{", callIDs{}},
+		{"organization", `{"organizationId":"o1","terminalGroupId":"t1"}`, callIDs{organizationID: "o1", terminalGroupID: "t1"}},
+		{"first of organizations", `{"organizationIds":["o1","o2"]}`, callIDs{organizationID: "o1"}},
+		{"order", `{"order":{"id":"d1"}}`, callIDs{orderID: "d1"}},
+		{"order info", `{"correlationId":"c1","orderInfo":{"id":"d2"}}`, callIDs{orderID: "d2", correlationID: "c1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractIDs([]byte(tt.body)); got != tt.want {
+				t.Errorf("extractIDs = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
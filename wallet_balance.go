@@ -14,5 +14,5 @@ type WalletBalance struct {
 	Id      string     `json:"id"`
 	Name    string     `json:"name"`
 	Type    WalletType `json:"type"`
-	Balance float64    `json:"balance"`
+	Balance Money      `json:"balance"`
 }
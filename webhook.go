@@ -1,12 +1,15 @@
 package iiko
 
 import (
+	"context"
 	"encoding/json"
-	"strings"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // WebhookEventType represents the type of webhook event
@@ -17,47 +20,24 @@ const (
 
 	DeliveryOrderUpdateWebhookEvent WebhookEventType = "DeliveryOrderUpdate"
 	DeliveryOrderErrorWebhookEvent  WebhookEventType = "DeliveryOrderError"
+
+	NomenclatureUpdateWebhookEvent            WebhookEventType = "NomenclatureUpdate"
+	BusinessHoursAndMappingUpdateWebhookEvent WebhookEventType = "BusinessHoursAndMappingUpdate"
 )
 
 // WebhookEvent represents a generic webhook event
 type WebhookEvent struct {
 	// Event Type of the webhook event
 	EventType      WebhookEventType `json:"eventType"`
-	EventTime      EventTime        `json:"eventTime"`
+	EventTime      Time             `json:"eventTime"`
 	OrganizationID uuid.UUID        `json:"organizationId"`
 	CorrelationID  uuid.UUID        `json:"correlationId"`
 	EventInfo      json.RawMessage  `json:"eventInfo"`
 }
 
-// EventTime кастомный тип для поддержки разных форматов времени
-type EventTime struct {
-	time.Time
-}
-
-func (et *EventTime) UnmarshalJSON(data []byte) error {
-	s := string(data)
-	s = strings.Trim(s, `"`) // remove 
-	if s == "null" || s == "" {
-		et.Time = time.Time{}
-		return nil
-	}
-	// parse RFC3339 format first for compatibility
-	t, err := time.Parse(time.RFC3339, s)
-	if err == nil {
-		et.Time = t
-		return nil
-	}
-	// iiko uses "2006-01-02 15:04:05.999" format that is not RFC3339
-	t, err = time.Parse("2006-01-02 15:04:05.999", s)
-	if err == nil {
-		et.Time = t
-		return nil
-	}
-
-	return fmt.Errorf("cannot parse eventTime: %s", s)
-}
-
-type WebhookHandlerFunc func(event *WebhookEvent) error
+// WebhookHandlerFunc handles one event. ctx carries the trace of the event, if
+// the server has a tracer provider set.
+type WebhookHandlerFunc func(ctx context.Context, event *WebhookEvent) error
 
 // WebhookHandler represents a webhook event handler function
 type WebhookHandler struct {
@@ -69,6 +49,9 @@ type WebhookHandler struct {
 type WebhookServer struct {
 	handlers map[WebhookEventType][]WebhookHandler
 	secret   string
+	logger   *slog.Logger
+	tracer   trace.Tracer
+	metrics  MetricsCollector
 }
 
 // NewWebhookServer creates a new webhook server
@@ -87,19 +70,63 @@ func (s *WebhookServer) RegisterHandler(eventType WebhookEventType, handlerName
 	})
 }
 
+// SetLogger makes the server log every handled event to l. With the debug
+// level enabled it also logs eventInfo, with customer personal data redacted.
+func (s *WebhookServer) SetLogger(l *slog.Logger) {
+	s.logger = l
+}
+
+// SetTracerProvider makes the server start an OpenTelemetry span for every
+// handled event, as a child of the context passed to HandleEvent. Handlers
+// receive the span in their context.
+func (s *WebhookServer) SetTracerProvider(tp trace.TracerProvider) {
+	s.tracer = tp.Tracer(tracerName)
+}
+
+// SetMetrics makes the server report event counts and handler latency to m.
+func (s *WebhookServer) SetMetrics(m MetricsCollector) {
+	s.metrics = m
+}
+
 // HandleEvent processes a webhook event using registered handlers
-func (s *WebhookServer) HandleEvent(event *WebhookEvent, secret string) error {
+func (s *WebhookServer) HandleEvent(ctx context.Context, event *WebhookEvent, secret string) (err error) {
+	// Requests without the secret are not events: they are neither traced,
+	// logged nor counted, so that they cannot flood the telemetry.
 	if s.secret != secret {
 		return fmt.Errorf("invalid secret")
 	}
 
+	if s.tracer != nil {
+		var span trace.Span
+		ctx, span = startEventSpan(ctx, s.tracer, event)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	if s.logger != nil || s.metrics != nil {
+		start := time.Now()
+		defer func() {
+			if s.logger != nil {
+				logEvent(ctx, s.logger, event, time.Since(start), err)
+			}
+			if s.metrics != nil {
+				s.metrics.ObserveWebhookEvent(event.EventType, time.Since(start), err)
+			}
+		}()
+	}
+
 	handlers, exists := s.handlers[event.EventType]
 	if !exists {
 		return fmt.Errorf("no handlers registered for event type: %s", event.EventType)
 	}
 
 	for _, handler := range handlers {
-		if err := handler.handle(event); err != nil {
+		if err := handler.handle(ctx, event); err != nil {
 			return fmt.Errorf("handler %s returned error for event type %s: %w", handler.name, event.EventType, err)
 		}
 	}
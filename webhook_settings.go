@@ -1,6 +1,10 @@
 package iiko
 
-import "github.com/google/uuid"
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
 
 // WebhookSettingsRequest represents the request structure for getting webhook settings
 type WebhookSettingsRequest struct {
@@ -112,28 +116,20 @@ type WebhookUpdateSettingsResponse struct {
 	CorrelationId uuid.UUID `json:"correlationId"`
 }
 
+var webhookSettingsEndpoint = NewEndpoint[WebhookSettingsRequest, WebhookSettingsResponse](EndpointInfo{Path: "/api/1/webhooks/settings", RequiresAuth: true, Idempotent: true})
+
 // WebhookSettings Retrieve webhook settings for organization
 //
 // iiko API: /api/1/webhooks/settings
-func (c *Client) WebhookSettings(req *WebhookSettingsRequest, opts ...Option) (*WebhookSettingsResponse, error) {
-	var response WebhookSettingsResponse
-
-	if err := c.post(true, "/api/1/webhooks/settings", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) WebhookSettings(ctx context.Context, req *WebhookSettingsRequest, opts ...Option) (*WebhookSettingsResponse, error) {
+	return webhookSettingsEndpoint.Call(ctx, c, req, opts...)
 }
 
+var webhookUpdateSettingsEndpoint = NewEndpoint[WebhookUpdateSettingsRequest, WebhookUpdateSettingsResponse](EndpointInfo{Path: "/api/1/webhooks/update_settings", RequiresAuth: true})
+
 // WebhookUpdateSettings Update webhook settings for organization
 //
 // iiko API: /api/1/webhooks/update_settings
-func (c *Client) WebhookUpdateSettings(req *WebhookUpdateSettingsRequest, opts ...Option) (*WebhookUpdateSettingsResponse, error) {
-	var response WebhookUpdateSettingsResponse
-
-	if err := c.post(true, "/api/1/webhooks/update_settings", req, &response, opts...); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+func (c *Client) WebhookUpdateSettings(ctx context.Context, req *WebhookUpdateSettingsRequest, opts ...Option) (*WebhookUpdateSettingsResponse, error) {
+	return webhookUpdateSettingsEndpoint.Call(ctx, c, req, opts...)
 }
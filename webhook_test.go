@@ -0,0 +1,65 @@
+package iiko
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWebhookSecretCheckedFirst(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		wantErr    bool
+		wantEvents bool
+	}{
+		{"valid secret", "secret", false, true},
+		{"invalid secret", "guess", true, false},
+		{"no secret", "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := &recordingProvider{}
+			var buf syncBuffer
+			m := NewPrometheusMetrics()
+
+			s := NewWebhookServer("secret")
+			s.SetTracerProvider(tp)
+			s.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+			s.SetMetrics(m)
+
+			handled := false
+			s.RegisterHandler(StopListUpdateWebhookEvent, "test", func(context.Context, *WebhookEvent) error {
+				handled = true
+				return nil
+			})
+
+			err := s.HandleEvent(context.Background(), &WebhookEvent{EventType: StopListUpdateWebhookEvent}, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if handled != tt.wantEvents {
+				t.Errorf("handled = %v", handled)
+			}
+
+			tp.tracer.mu.Lock()
+			spans := len(tp.tracer.spans)
+			tp.tracer.mu.Unlock()
+			if (spans > 0) != tt.wantEvents {
+				t.Errorf("%d spans recorded", spans)
+			}
+			if records := buf.records(t); (len(records) > 0) != tt.wantEvents {
+				t.Errorf("%d log records", len(records))
+			}
+
+			var text strings.Builder
+			if err := m.WriteText(&text); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(text.String(), "iiko_webhook_events_total{"); got != tt.wantEvents {
+				t.Errorf("webhook events counted = %v:\n%s", got, text.String())
+			}
+		})
+	}
+}